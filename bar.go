@@ -1,12 +1,15 @@
 package mpb
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
+	"github.com/james-antill/mpb/cwriter"
 	"github.com/james-antill/mpb/decor"
 	"github.com/mattn/go-runewidth"
 )
@@ -30,11 +33,20 @@ type fmtFillSegments []rune
 
 // Bar represents a progress Bar
 type Bar struct {
+	// current is incremented atomically by Incr, and folded into state by
+	// b.server before any op is run. Must be first field for 64-bit
+	// alignment on 32-bit platforms.
+	current int64
+	// current2 is secondary counter, incremented by Incr2 the same way
+	current2 int64
+
 	// quit channel to request b.server to quit
 	quit chan struct{}
 	// done channel is receiveable after b.server has been quit
 	done chan struct{}
-	ops  chan func(*state)
+	// ops may be buffered (see WithOpsBuffer), so a queued op may be never
+	// run, if b.server quits. Waiting for op's result must select on done too.
+	ops chan func(*state)
 
 	// following are used after b.done is receiveable
 	cacheState state
@@ -43,6 +55,24 @@ type Bar struct {
 const rollAveSlots = 8
 const rollAveTime = 2 * time.Second
 
+// speed samples for decor.SpeedWindow, a sample per speedSampleEvery at most,
+// so windows up to speedSamples*speedSampleEvery (30s) are covered
+const speedSamples = 300
+const speedSampleEvery = 100 * time.Millisecond
+
+// smoothTailFrom is percentage, above which ETA is kept monotonic, if bar has
+// WithSmoothETATail option
+const smoothTailFrom = 95
+
+// Segment is a range of the bar, which is filled independently from other
+// segments. Useful to display parallel download of a file in ranges.
+type Segment struct {
+	// Start and End of the range, in the same units as bar's total
+	Start, End int64
+	// Current amount done of the range, counting from Start
+	Current int64
+}
+
 type (
 	refill struct {
 		char rune
@@ -58,42 +88,106 @@ type (
 		current        int64
 		trimLeftSpace  bool
 		trimRightSpace bool
+		fixedWidth     bool
+		countdown      bool
+		opsBuffer      int
+		segments       []Segment
+		attempt        int
+		maxAttempts    int
+		phase          string
+		mark           int64
+		seqIndex       int
+		seqTotal       int
+		smoothETATail  bool
+		maxSpeed       float64
+		growingTotal   bool
+		completedFill  []byte
+		minBarWidth    int
+		rightAlign     bool
+		widthCond      *runewidth.Condition
+		refreshRate    time.Duration
+		current2       int64
+		tailETA        time.Duration
+		tailETATime    time.Time
 		started        bool
 		completed      bool
 		aborted        bool
 
+		// shown by summary table, see WithSummaryTable
+		name string
+
 		// Statistics ...
 		startTime time.Time
+		// if not zero, time the bar has completed or aborted at
+		doneTime time.Time
+		// if not zero, time accounting is paused since then
+		pausedAt time.Time
+		// if not zero, bar is driven by time elapsed since then
+		timerStart time.Time
 		// For rolling average ETA
 		rollTime  [rollAveSlots]time.Time
 		rollTotal [rollAveSlots]int64
 		rollOff   int
+		// For moving average of items per second, smoothed by etaAlpha
+		ewmaRate float64
+		ewmaTime time.Time
+		// (time, current) samples, from the oldest, for decor.SpeedWindow
+		samples []decor.Sample
+		// window of samples to keep, in ns, set atomically by wantSamples;
+		// no samples are kept, until it's requested
+		sampleWindow *int64
+		wantSamples  func(time.Duration)
 
 		appendFuncs   []decor.DecoratorFunc
 		prependFuncs  []decor.DecoratorFunc
-		simpleSpinner func() byte
-		refill        *refill
+		simpleSpinner bool
+		// spinner frame, advanced by render op only
+		spinnerIndex int
+		spinnerDone  string
+		marquee      bool
+		refill       *refill
+		// rendered instead of spinner, once bar has been aborted
+		spinnerAborted string
+		// number of render ops and time of the last one, see RenderStats
+		renderCount int64
+		lastRender  time.Time
+		// byte segments of format and fmtFill, cached by updateFormat
+		fmtBytes     fmtByteSegments
+		fmtFillBytes fmtByteSegments
 	}
 )
 
 func newBar(total int64, wg *sync.WaitGroup, cancel <-chan struct{}, options ...BarOption) *Bar {
 	s := state{
-		total:    total,
-		etaAlpha: etaAlpha,
+		total:          total,
+		etaAlpha:       etaAlpha,
+		spinnerDone:    "+",
+		spinnerAborted: "x",
 	}
 
 	if total <= 0 {
-		s.simpleSpinner = getSpinner()
+		s.simpleSpinner = true
 	}
 
 	for _, opt := range options {
 		opt(&s)
 	}
 
+	s.sampleWindow = new(int64)
+	s.wantSamples = func(window time.Duration) {
+		// the longest window requested is kept
+		for {
+			old := atomic.LoadInt64(s.sampleWindow)
+			if int64(window) <= old || atomic.CompareAndSwapInt64(s.sampleWindow, old, int64(window)) {
+				return
+			}
+		}
+	}
+
 	b := &Bar{
 		quit: make(chan struct{}),
 		done: make(chan struct{}),
-		ops:  make(chan func(*state)),
+		ops:  make(chan func(*state), s.opsBuffer),
 	}
 
 	go b.server(s, wg, cancel)
@@ -101,6 +195,17 @@ func newBar(total int64, wg *sync.WaitGroup, cancel <-chan struct{}, options ...
 	return b
 }
 
+// newDeadBar returns a bar, which has quit already. Its methods don't block,
+// because both quit and done are closed.
+func newDeadBar() *Bar {
+	closed := make(chan struct{})
+	close(closed)
+	return &Bar{
+		quit: closed,
+		done: closed,
+	}
+}
+
 // RemoveAllPrependers removes all prepend functions
 func (b *Bar) RemoveAllPrependers() {
 	select {
@@ -125,7 +230,30 @@ func (b *Bar) RemoveAllAppenders() {
 
 // ProxyReader wrapper for io operations, like io.Copy
 func (b *Bar) ProxyReader(r io.Reader) *Reader {
-	return &Reader{r, b}
+	return &Reader{Reader: r, bars: []*Bar{b}}
+}
+
+// ProxyReaderFunc is like ProxyReader, but also calls cb with number of bytes
+// of each read. Could be used to feed a metrics system.
+func (b *Bar) ProxyReaderFunc(r io.Reader, cb func(n int)) *Reader {
+	return &Reader{Reader: r, bars: []*Bar{b}, cb: cb}
+}
+
+// ProxyReaderBuffered is like ProxyReader, but accumulates read bytes and
+// increments the bar only once flushEvery bytes have been read. Remainder is
+// flushed on EOF (or any other read error) and on Close. Useful for sources
+// with tiny reads, to reduce per read accounting overhead.
+func (b *Bar) ProxyReaderBuffered(r io.Reader, flushEvery int64) *Reader {
+	return &Reader{Reader: r, bars: []*Bar{b}, flushEvery: flushEvery}
+}
+
+// ProxyReaderLimit is like ProxyReader, but guards against sources sending
+// more than the bar's total, like a server lying about Content-Length. Once
+// more than total bytes have been read, ErrReadLimit is returned and the bar
+// is aborted, unless KeepOnError is set. Total is taken once, when the reader is created; if it's
+// unknown, there's no limit.
+func (b *Bar) ProxyReaderLimit(r io.Reader) *Reader {
+	return &Reader{Reader: r, bars: []*Bar{b}, limit: b.Total()}
 }
 
 // Increment shorthand for b.Incr(1)
@@ -135,32 +263,45 @@ func (b *Bar) Increment() {
 
 // Update updates the startTime/timeElapsed for ETA/Nsec
 func (b *Bar) Update() {
-	b.Incr(0)
+	select {
+	case b.ops <- func(s *state) {
+		s.start()
+		if !s.timerStart.IsZero() {
+			b.tickTimer(s)
+		}
+	}:
+	case <-b.quit:
+		return
+	}
 }
 
-// Incr increments progress bar
+// tickTimer sets current of timer bar to time elapsed since its start, see
+// Progress.AddTimerBar
+func (b *Bar) tickTimer(s *state) {
+	elapsed := int64(time.Since(s.timerStart))
+	if elapsed > s.total {
+		elapsed = s.total
+	}
+	atomic.StoreInt64(&b.current, elapsed)
+	b.fold(s)
+}
+
+// Incr increments progress bar. It doesn't go through bar's ops channel, so
+// it's cheap to call very often. Increments are accounted by bar's goroutine
+// on its next op, which is at least once per render cycle.
 func (b *Bar) Incr(n int) {
 	if n < 0 {
 		return
 	}
+	if n == 0 {
+		b.Update()
+		return
+	}
 	select {
-	case b.ops <- func(s *state) {
-		if s.current == 0 && !s.started {
-			s.startTime = time.Now()
-			s.initETA()
-			s.started = true
-		}
-		sum := s.current + int64(n)
-		s.updateETA(int64(n))
-		if s.total > 0 && sum >= s.total {
-			s.current = s.total
-			s.completed = true
-			return
-		}
-		s.current = sum
-	}:
 	case <-b.quit:
 		return
+	default:
+		atomic.AddInt64(&b.current, int64(n))
 	}
 }
 
@@ -179,11 +320,238 @@ func (b *Bar) ResumeFill(r rune, till int64) {
 	}
 }
 
+// SetSegments makes the bar to fill each of segs independently, instead of
+// filling from 0 to current. Use IncrSegment to increment a segment.
+func (b *Bar) SetSegments(segs []Segment) {
+	segs = append([]Segment(nil), segs...)
+	select {
+	case b.ops <- func(s *state) {
+		s.segments = segs
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// currentNow returns current value lock free, without waiting for b.server,
+// clamped to total only once b.server has quit.
+func (b *Bar) currentNow() int64 {
+	select {
+	case <-b.done:
+		return b.cacheState.current
+	default:
+		return atomic.LoadInt64(&b.current)
+	}
+}
+
+// Incr2 increments secondary counter, which isn't rendered as progress, but
+// is available to decorators as Statistics.Current2. Useful to count items,
+// while the bar itself counts bytes (see decor.AvgSize).
+func (b *Bar) Incr2(n int) {
+	if n <= 0 {
+		return
+	}
+	select {
+	case <-b.quit:
+		return
+	default:
+		atomic.AddInt64(&b.current2, int64(n))
+	}
+}
+
+// IncrSegment increments segment with index id, and the bar itself by n
+func (b *Bar) IncrSegment(id int, n int) {
+	if n < 0 {
+		return
+	}
+	select {
+	case b.ops <- func(s *state) {
+		if id < 0 || id >= len(s.segments) {
+			return
+		}
+		seg := &s.segments[id]
+		seg.Current += int64(n)
+		if max := seg.End - seg.Start; seg.Current > max {
+			seg.Current = max
+		}
+	}:
+		b.Incr(n)
+	case <-b.quit:
+		return
+	}
+}
+
+// SetAttempt sets retry attempt n of max, to be shown by decor.Attempt
+func (b *Bar) SetAttempt(n, max int) {
+	select {
+	case b.ops <- func(s *state) {
+		s.attempt = n
+		s.maxAttempts = max
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// Abort marks the bar as aborted and stops it at its current value, as if it
+// had been canceled. Decorators can tell by Statistics.Aborted.
+func (b *Bar) Abort() {
+	select {
+	case b.ops <- func(s *state) {
+		s.aborted = true
+		b.Complete()
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// SetSequence sets position of the bar's item within a known set, like
+// 3rd file of 16, to be shown by decor.Sequence
+func (b *Bar) SetSequence(index, total int) {
+	select {
+	case b.ops <- func(s *state) {
+		s.seqIndex = index
+		s.seqTotal = total
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// SetPhase sets textual label of current phase, like "verifying", to be
+// shown by decor.Phase
+func (b *Bar) SetPhase(name string) {
+	select {
+	case b.ops <- func(s *state) {
+		s.phase = name
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// PauseETA freezes time accounting of the bar, like when transfer waits on
+// rate limiter, so elapsed time, speed and ETA reflect active time only, until
+// ResumeETA is called.
+func (b *Bar) PauseETA() {
+	select {
+	case b.ops <- func(s *state) {
+		if s.pausedAt.IsZero() {
+			s.pausedAt = time.Now()
+		}
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// ResumeETA resumes time accounting, paused by PauseETA.
+func (b *Bar) ResumeETA() {
+	select {
+	case b.ops <- func(s *state) {
+		if !s.pausedAt.IsZero() {
+			s.shiftTimes(time.Now())
+			s.pausedAt = time.Time{}
+		}
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// Mark records current value, to be used by decor.SinceMark
+func (b *Bar) Mark() {
+	mark := atomic.LoadInt64(&b.current)
+	select {
+	case b.ops <- func(s *state) {
+		if s.total > 0 && mark > s.total {
+			mark = s.total
+		}
+		s.mark = mark
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// SetTotal sets new total, so the bar can be reused for the next phase.
+// The bar completes, if current has reached the new total already. If the new
+// total is less than current, current is clamped down to it, so the bar shows
+// 100% rather than blanks. Bar, which has reached its old total, but hasn't
+// been rendered as completed yet, is un-completed by greater total. For totals
+// growing as work is discovered, see AddTotal and WithGrowingTotal. Total
+// <= 0 turns the bar into spinner, for a phase of unknown size.
+func (b *Bar) SetTotal(total int64) {
+	select {
+	case b.ops <- func(s *state) {
+		b.setTotal(s, total)
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// AddTotal adds n to the bar's total, a delta form of SetTotal.
+func (b *Bar) AddTotal(n int64) {
+	select {
+	case b.ops <- func(s *state) {
+		b.setTotal(s, s.total+n)
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// SetPercent sets current to p percent of total, for backends reporting
+// progress as percentage. p is clamped to [0, 100]. It's a no-op, if total is
+// unknown. It isn't meant to be mixed with Incr.
+func (b *Bar) SetPercent(p float64) {
+	select {
+	case b.ops <- func(s *state) {
+		if s.total <= 0 {
+			return
+		}
+		cur := int64(p / 100 * float64(s.total))
+		if cur < 0 {
+			cur = 0
+		} else if cur > s.total {
+			cur = s.total
+		}
+		atomic.StoreInt64(&b.current, cur)
+		if cur < s.current {
+			// fold accounts increments only
+			s.current = cur
+		}
+		b.fold(s)
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+func (b *Bar) setTotal(s *state, total int64) {
+	s.total = total
+	// bar switches between fill and spinner, as total becomes known or not
+	s.simpleSpinner = total <= 0
+	b.fold(s)
+	if total > 0 && s.current > total {
+		s.current = total
+		atomic.StoreInt64(&b.current, total)
+	}
+	s.completed = !s.growingTotal && total > 0 && s.current >= total
+}
+
 func (b *Bar) NumOfAppenders() int {
 	result := make(chan int, 1)
 	select {
 	case b.ops <- func(s *state) { result <- len(s.appendFuncs) }:
-		return <-result
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+			return len(b.cacheState.appendFuncs)
+		}
 	case <-b.done:
 		return len(b.cacheState.appendFuncs)
 	}
@@ -193,7 +561,12 @@ func (b *Bar) NumOfPrependers() int {
 	result := make(chan int, 1)
 	select {
 	case b.ops <- func(s *state) { result <- len(s.prependFuncs) }:
-		return <-result
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+			return len(b.cacheState.prependFuncs)
+		}
 	case <-b.done:
 		return len(b.cacheState.prependFuncs)
 	}
@@ -204,7 +577,12 @@ func (b *Bar) ID() int {
 	result := make(chan int, 1)
 	select {
 	case b.ops <- func(s *state) { result <- s.id }:
-		return <-result
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+			return b.cacheState.id
+		}
 	case <-b.done:
 		return b.cacheState.id
 	}
@@ -214,7 +592,12 @@ func (b *Bar) Current() int64 {
 	result := make(chan int64, 1)
 	select {
 	case b.ops <- func(s *state) { result <- s.current }:
-		return <-result
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+			return b.cacheState.current
+		}
 	case <-b.done:
 		return b.cacheState.current
 	}
@@ -224,12 +607,113 @@ func (b *Bar) Total() int64 {
 	result := make(chan int64, 1)
 	select {
 	case b.ops <- func(s *state) { result <- s.total }:
-		return <-result
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+			return b.cacheState.total
+		}
 	case <-b.done:
 		return b.cacheState.total
 	}
 }
 
+// AdoptFrom makes b to continue, where other has left off, like a retry of
+// failed download on a fresh bar: current amount, start time and speed
+// history are copied from other, so ETA isn't reset. It's meant to be called
+// before b is incremented, as b's own progress is overwritten.
+func (b *Bar) AdoptFrom(other *Bar) {
+	from := other.snapshot()
+	select {
+	case b.ops <- func(s *state) {
+		s.current = from.current
+		atomic.StoreInt64(&b.current, from.current)
+		s.started = from.started
+		s.startTime = from.startTime
+		s.rollTime = from.rollTime
+		s.rollTotal = from.rollTotal
+		s.rollOff = from.rollOff
+		s.ewmaRate = from.ewmaRate
+		s.ewmaTime = from.ewmaTime
+		s.samples = from.samples
+		if s.total > 0 && s.current >= s.total && !s.growingTotal {
+			s.completed = true
+		}
+	}:
+	case <-b.quit:
+		return
+	}
+}
+
+// snapshot returns copy of b's state, safe to be used by other goroutines
+func (b *Bar) snapshot() state {
+	result := make(chan state, 1)
+	select {
+	case b.ops <- func(s *state) {
+		st := *s
+		st.samples = append([]decor.Sample(nil), s.samples...)
+		result <- st
+	}:
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+		}
+	case <-b.done:
+	}
+	st := b.cacheState
+	st.samples = append([]decor.Sample(nil), st.samples...)
+	return st
+}
+
+// RenderStats returns how many times the bar's line has been written to the
+// terminal, and when was the last time. Renders of bars, skipped because they
+// don't fit terminal, aren't counted. Could be useful to diagnose bars starved
+// under load, or never shown at all.
+func (b *Bar) RenderStats() (count int64, lastRender time.Time) {
+	type renderStats struct {
+		count int64
+		last  time.Time
+	}
+	result := make(chan renderStats, 1)
+	select {
+	case b.ops <- func(s *state) { result <- renderStats{s.renderCount, s.lastRender} }:
+		select {
+		case r := <-result:
+			return r.count, r.last
+		case <-b.done:
+		}
+	case <-b.done:
+	}
+	return b.cacheState.renderCount, b.cacheState.lastRender
+}
+
+// Speed returns current moving-average speed in items per second, the number
+// rendered by decor.Nsec, for programmatic checks like alerts.
+func (b *Bar) Speed() float64 {
+	return b.statistics().Speed()
+}
+
+func (b *Bar) statistics() *decor.Statistics {
+	result := make(chan *decor.Statistics, 1)
+	select {
+	case b.ops <- func(s *state) {
+		stat := newStatistics(s)
+		// samples are modified in place by b.server
+		stat.Samples = append([]decor.Sample(nil), stat.Samples...)
+		result <- stat
+	}:
+		select {
+		case r := <-result:
+			return r
+		case <-b.done:
+			return newStatistics(&b.cacheState)
+		}
+	case <-b.done:
+		return newStatistics(&b.cacheState)
+	}
+}
+
 // InProgress returns true, while progress is running.
 // Can be used as condition in for loop
 func (b *Bar) InProgress() bool {
@@ -276,9 +760,13 @@ func (b *Bar) server(s state, wg *sync.WaitGroup, cancel <-chan struct{}) {
 	for {
 		select {
 		case op := <-b.ops:
+			b.fold(&s)
 			op(&s)
+			s.updateDoneTime()
 		case <-b.quit:
+			b.fold(&s)
 			s.completed = true
+			s.updateDoneTime()
 			return
 		case <-cancel:
 			s.aborted = true
@@ -288,37 +776,57 @@ func (b *Bar) server(s state, wg *sync.WaitGroup, cancel <-chan struct{}) {
 	}
 }
 
-func (b *Bar) render(tw int, flushed chan struct{}, prependWs, appendWs *widthSync) <-chan []byte {
+func (b *Bar) render(tw int, visible bool, flushed chan struct{}, prependWs, appendWs *widthSync) <-chan []byte {
 	ch := make(chan []byte, 1)
+	go b.renderTo(ch, tw, visible, flushed, prependWs, appendWs)
+	return ch
+}
 
-	go func() {
-		defer func() {
-			// recovering if external decorators panic
-			if p := recover(); p != nil {
-				ch <- []byte(fmt.Sprintln(p))
-			}
-			close(ch)
-		}()
-		var st state
-		result := make(chan state, 1)
+// renderTo sends rendered bar to ch, which must be buffered, and closes it.
+// Bar isn't visible, if its line is going to be skipped, because it doesn't
+// fit terminal. It's still rendered, so width sync works as usual.
+func (b *Bar) renderTo(ch chan<- []byte, tw int, visible bool, flushed chan struct{}, prependWs, appendWs *widthSync) {
+	defer func() {
+		// recovering if external decorators panic
+		if p := recover(); p != nil {
+			ch <- []byte(fmt.Sprintln(p))
+		}
+		close(ch)
+	}()
+	var st state
+	result := make(chan state, 1)
+	select {
+	case b.ops <- func(s *state) {
+		if s.smoothETATail {
+			s.updateTailETA()
+		}
+		if visible {
+			s.renderCount++
+			s.lastRender = time.Now()
+		}
+		st := *s
+		// samples are modified in place, while st is drawn
+		st.samples = append([]decor.Sample(nil), s.samples...)
+		result <- st
+		if s.simpleSpinner {
+			s.spinnerIndex++
+		}
+		if s.completed {
+			<-flushed
+			b.Complete()
+		}
+	}:
 		select {
-		case b.ops <- func(s *state) {
-			result <- *s
-			if s.completed {
-				<-flushed
-				b.Complete()
-			}
-		}:
-			st = <-result
+		case st = <-result:
 		case <-b.done:
 			st = b.cacheState
 		}
-		buf := draw(&st, tw, prependWs, appendWs)
-		buf = append(buf, '\n')
-		ch <- buf
-	}()
-
-	return ch
+	case <-b.done:
+		st = b.cacheState
+	}
+	buf := draw(&st, tw, prependWs, appendWs)
+	buf = append(buf, '\n')
+	ch <- buf
 }
 
 func (s *state) updateFormat(format string, fillFmt []string) {
@@ -327,6 +835,11 @@ func (s *state) updateFormat(format string, fillFmt []string) {
 		format = format[n:]
 	}
 
+	defer func() {
+		s.fmtBytes = fmtRunesToByteSegments(s.format[:])
+		s.fmtFillBytes = fmtRunesToByteSegments(s.fmtFill)
+	}()
+
 	if len(fillFmt) < 1 {
 		return
 	}
@@ -338,6 +851,88 @@ func (s *state) updateFormat(format string, fillFmt []string) {
 	s.format[rFill] = s.fmtFill[len(s.fmtFill)-1]
 }
 
+// fold accounts increments made by Incr since the last fold
+func (b *Bar) fold(s *state) {
+	s.current2 = atomic.LoadInt64(&b.current2)
+	cur := atomic.LoadInt64(&b.current)
+	if s.total > 0 && cur > s.total {
+		cur = s.total
+	}
+	n := cur - s.current
+	if n <= 0 {
+		return
+	}
+	s.start()
+	s.updateETA(n)
+	s.current = cur
+	if s.total > 0 && cur >= s.total && !s.growingTotal {
+		s.completed = true
+	}
+}
+
+func (s *state) start() {
+	if s.started {
+		return
+	}
+	s.startTime = s.now()
+	s.initETA()
+	s.started = true
+}
+
+// shiftTimes moves time accounting forward to resume time, as if the bar
+// hasn't been running between pausedAt and resume. Times taken while paused
+// are moved to resume time.
+func (s *state) shiftTimes(resume time.Time) {
+	shift := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		if t.Before(s.pausedAt) {
+			return t.Add(resume.Sub(s.pausedAt))
+		}
+		return resume
+	}
+	s.startTime = shift(s.startTime)
+	for i, t := range s.rollTime {
+		s.rollTime[i] = shift(t)
+	}
+	s.ewmaTime = shift(s.ewmaTime)
+	s.tailETATime = shift(s.tailETATime)
+	for i := range s.samples {
+		s.samples[i].Time = shift(s.samples[i].Time)
+	}
+}
+
+// now returns current time of time accounting, which doesn't pass while
+// paused.
+func (s *state) now() time.Time {
+	if !s.pausedAt.IsZero() {
+		return s.pausedAt
+	}
+	return time.Now()
+}
+
+// updateDoneTime records the time the bar has completed or aborted at, so
+// elapsed time doesn't grow afterwards. It's cleared, if the bar is running
+// again, like after SetTotal raised total.
+func (s *state) updateDoneTime() {
+	if !s.completed && !s.aborted {
+		s.doneTime = time.Time{}
+		return
+	}
+	if s.doneTime.IsZero() {
+		s.doneTime = time.Now()
+	}
+}
+
+// pausedFor returns for how long time accounting has been paused so far
+func (s *state) pausedFor() time.Duration {
+	if s.pausedAt.IsZero() {
+		return 0
+	}
+	return time.Since(s.pausedAt)
+}
+
 func (s *state) initETA() {
 	s.rollTime[0] = s.startTime
 }
@@ -347,14 +942,87 @@ func (s *state) updateETA(amount int64) {
 		return
 	}
 
-	dur := time.Since(s.rollTime[s.rollOff])
+	now := s.now()
+	dur := now.Sub(s.rollTime[s.rollOff])
 	if dur > rollAveTime {
 		s.rollOff = (s.rollOff + 1) % rollAveSlots
-		s.rollTime[s.rollOff] = time.Now()
+		s.rollTime[s.rollOff] = now
 		s.rollTotal[s.rollOff] = 0
 	}
 
 	s.rollTotal[s.rollOff] += amount
+	s.addSample(s.current + amount)
+
+	since := s.ewmaTime
+	if since.IsZero() {
+		since = s.startTime
+	}
+	if dt := now.Sub(since).Seconds(); dt > 0 {
+		sample := float64(amount) / dt
+		if s.ewmaRate == 0 {
+			s.ewmaRate = sample
+		} else {
+			s.ewmaRate = s.etaAlpha*sample + (1-s.etaAlpha)*s.ewmaRate
+		}
+		s.ewmaTime = now
+	}
+}
+
+// addSample records current in samples, if they have been requested by
+// decor.SpeedWindow. Samples closer than speedSampleEvery to the last one are
+// merged into it, and ones older than requested window are dropped.
+func (s *state) addSample(current int64) {
+	if s.sampleWindow == nil {
+		return
+	}
+	window := time.Duration(atomic.LoadInt64(s.sampleWindow))
+	if window == 0 {
+		return
+	}
+	now := s.now()
+	if n := len(s.samples); n > 0 && now.Sub(s.samples[n-1].Time) < speedSampleEvery {
+		s.samples[n-1].Current = current
+		return
+	}
+	// drop the oldest ones, in place
+	var drop int
+	if len(s.samples) == speedSamples {
+		drop = 1
+	}
+	cutoff := now.Add(-window)
+	for drop < len(s.samples) && s.samples[drop].Time.Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		n := copy(s.samples, s.samples[drop:])
+		s.samples = s.samples[:n]
+	}
+	s.samples = append(s.samples, decor.Sample{Time: now, Current: current})
+}
+
+// updateTailETA makes ETA monotonic, once progress is above smoothTailFrom
+// percent: the new estimate is never greater than the previous one, minus
+// the time passed since.
+func (s *state) updateTailETA() {
+	beg, cur := s.getDataETA()
+	stat := &decor.Statistics{Total: s.total, Current: s.current,
+		RollStartTime: beg.Add(s.pausedFor()), RollCurrent: cur}
+	if cur == 0 || stat.Percentage() < smoothTailFrom {
+		s.tailETATime = time.Time{}
+		return
+	}
+	eta := stat.Eta()
+	now := s.now()
+	if !s.tailETATime.IsZero() {
+		if prev := s.tailETA - now.Sub(s.tailETATime); eta > prev {
+			eta = prev
+		}
+	}
+	if eta < 0 {
+		eta = 0
+	}
+	s.tailETA = eta
+	s.tailETATime = now
 }
 
 func (s *state) getDataETA() (time.Time, int64) {
@@ -376,6 +1044,27 @@ func (s *state) getDataETA() (time.Time, int64) {
 	return beg, cur
 }
 
+// getRecentRates returns per second rates of the rolled over slots, from the
+// oldest to the newest one. The current slot is still being filled, so it's
+// skipped.
+func (s *state) getRecentRates() []int64 {
+	var rates []int64
+	off := s.rollOff
+	for i := 1; i < rollAveSlots; i++ {
+		off = (off + 1) % rollAveSlots
+		next := (off + 1) % rollAveSlots
+		if s.rollTime[off].IsZero() || s.rollTime[next].IsZero() {
+			continue
+		}
+		dur := s.rollTime[next].Sub(s.rollTime[off])
+		if dur <= 0 {
+			continue
+		}
+		rates = append(rates, int64(float64(s.rollTotal[off])/dur.Seconds()))
+	}
+	return rates
+}
+
 func draw(s *state, termWidth int, prependWs, appendWs *widthSync) []byte {
 	if len(s.prependFuncs) != len(prependWs.Listen) || len(s.appendFuncs) != len(appendWs.Listen) {
 		return []byte{}
@@ -386,22 +1075,36 @@ func draw(s *state, termWidth int, prependWs, appendWs *widthSync) []byte {
 
 	stat := newStatistics(s)
 
+	// prepend and append blocks share pooled scratch buffer, as they're
+	// copied into the returned line anyway
+	scratch := scratchPool.Get().(*[]byte)
+	blocks := (*scratch)[:0]
+	defer func() {
+		*scratch = blocks
+		scratchPool.Put(scratch)
+	}()
+
 	// render prepend functions to the left of the bar
-	var prependBlock []byte
 	for i, f := range s.prependFuncs {
-		prependBlock = append(prependBlock,
-			[]byte(f(stat, prependWs.Listen[i], prependWs.Result[i]))...)
+		blocks = append(blocks, f(stat, prependWs.Listen[i], prependWs.Result[i])...)
 	}
+	prependLen := len(blocks)
 
 	// render append functions to the right of the bar
-	var appendBlock []byte
 	for i, f := range s.appendFuncs {
-		appendBlock = append(appendBlock,
-			[]byte(f(stat, appendWs.Listen[i], appendWs.Result[i]))...)
+		blocks = append(blocks, f(stat, appendWs.Listen[i], appendWs.Result[i])...)
 	}
 
-	prependCount := utf8.RuneCount(prependBlock)
-	appendCount := utf8.RuneCount(appendBlock)
+	prependBlock, appendBlock := blocks[:prependLen], blocks[prependLen:]
+
+	// escape sequences, like colors of decor.AutoColor, take no columns
+	prependCount := utf8.RuneCount(cwriter.StripEscapes(prependBlock))
+	appendCount := utf8.RuneCount(cwriter.StripEscapes(appendBlock))
+	if s.widthCond != nil {
+		// ambiguous runes may be double width, see WithAmbiguousWidth
+		prependCount = s.stringWidth(cwriter.StripEscapes(prependBlock))
+		appendCount = s.stringWidth(cwriter.StripEscapes(appendBlock))
+	}
 
 	var leftSpace, rightSpace []byte
 	space := []byte{' '}
@@ -416,27 +1119,121 @@ func draw(s *state, termWidth int, prependWs, appendWs *widthSync) []byte {
 	}
 
 	var barBlock []byte
-	buf := make([]byte, 0, termWidth)
-	segments := fmtRunesToByteSegments(s.format[:])
-	fmtFill := fmtRunesToByteSegments(s.fmtFill)
+	segments, fmtFill := s.fmtBytes, s.fmtFillBytes
+	if segments == nil {
+		segments = fmtRunesToByteSegments(s.format[:])
+		fmtFill = fmtRunesToByteSegments(s.fmtFill)
+	}
 
-	if s.simpleSpinner != nil {
-		for _, block := range [...][]byte{segments[rLeft], {s.simpleSpinner()}, segments[rRight]} {
+	if s.simpleSpinner && !s.marquee {
+		i := s.spinnerIndex % len(spinnerChars)
+		spinner := spinnerChars[i : i+1]
+		if s.aborted {
+			spinner = []byte(s.spinnerAborted)
+		} else if s.completed {
+			spinner = []byte(s.spinnerDone)
+		}
+		for _, block := range [...][]byte{segments[rLeft], spinner, segments[rRight]} {
 			barBlock = append(barBlock, block...)
 		}
 	} else {
-		barBlock = fillBar(s.total, s.current, s.width, segments,
-			fmtFill, s.refill)
-		barCount := runewidth.StringWidth(string(barBlock))
+		fill := func(width int) []byte {
+			if s.simpleSpinner {
+				return fillMarquee(width, segments, s.spinnerIndex,
+					s.completed && !s.aborted)
+			}
+			if len(s.segments) > 0 {
+				return fillSegments(s.total, s.current, width, segments, s.segments)
+			}
+			return fillBar(s.total, s.current, width, segments,
+				fmtFill, s.refill, s.countdown, s.completedFill)
+		}
+		barBlock = fill(s.width)
+		barCount := s.stringWidth(barBlock)
 		totalCount := prependCount + barCount + appendCount
-		if totalCount > termWidth {
+		if totalCount > termWidth && !s.fixedWidth {
 			shrinkWidth := termWidth - prependCount - appendCount
-			barBlock = fillBar(s.total, s.current, shrinkWidth, segments,
-				fmtFill, s.refill)
+			if shrinkWidth < s.minBarWidth {
+				// bar has priority over decorators: keep minBarWidth
+				// columns of bar, truncating appenders first
+				shrinkWidth = s.minBarWidth
+				avail := termWidth - shrinkWidth - len(leftSpace) - len(rightSpace)
+				prependBlock, appendBlock = truncateBlocks(s.widthCond, prependBlock, appendBlock, avail)
+				// truncated blocks may be narrower than avail, if a wide
+				// rune didn't fit, so right align pad needs their new widths
+				prependCount = s.stringWidth(cwriter.StripEscapes(prependBlock)) + len(leftSpace)
+				appendCount = s.stringWidth(cwriter.StripEscapes(appendBlock)) + len(rightSpace)
+			}
+			barBlock = fill(shrinkWidth)
 		}
 	}
 
-	return concatenateBlocks(buf, prependBlock, leftSpace, barBlock, rightSpace, appendBlock)
+	var pad []byte
+	if s.rightAlign {
+		// pad between bar and appenders, pushing the latter to termWidth
+		used := prependCount + s.stringWidth(barBlock) + appendCount
+		if n := termWidth - used; n > 0 {
+			pad = bytes.Repeat(space, n)
+		}
+	}
+
+	// +1 for new line, appended by renderTo
+	buf := make([]byte, 0, len(prependBlock)+len(barBlock)+len(pad)+len(appendBlock)+3)
+	return concatenateBlocks(buf, prependBlock, leftSpace, barBlock, pad, rightSpace, appendBlock)
+}
+
+// truncateBlocks truncates prepend and append blocks to fit width columns
+// together. Append block is truncated first.
+func truncateBlocks(cond *runewidth.Condition, prependBlock, appendBlock []byte, width int) ([]byte, []byte) {
+	if cond == nil {
+		cond = runewidth.DefaultCondition
+	}
+	if width < 0 {
+		width = 0
+	}
+	prependWidth := cond.StringWidth(string(cwriter.StripEscapes(prependBlock)))
+	if prependWidth > width {
+		prependBlock = truncateVisible(cond, prependBlock, width)
+		prependWidth = width
+	}
+	appendWidth := width - prependWidth
+	if cond.StringWidth(string(cwriter.StripEscapes(appendBlock))) > appendWidth {
+		appendBlock = truncateVisible(cond, appendBlock, appendWidth)
+	}
+	return prependBlock, appendBlock
+}
+
+// truncateVisible truncates visible text of b to width columns. Escape
+// sequences are kept, even past width, so none is cut in half and trailing
+// reset of colors, like "\x1b[0m", isn't lost.
+func truncateVisible(cond *runewidth.Condition, b []byte, width int) []byte {
+	var p cwriter.EscapeParser
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if !p.Visible(b[i]) {
+			out = append(out, b[i])
+			continue
+		}
+		r, n := utf8.DecodeRune(b[i:])
+		if w := cond.RuneWidth(r); w <= width {
+			out = append(out, b[i:i+n]...)
+			width -= w
+		} else {
+			// nothing visible fits after it
+			width = 0
+		}
+		i += n - 1
+	}
+	return out
+}
+
+// stringWidth returns width of b in columns, measured as configured by
+// WithAmbiguousWidth
+func (s *state) stringWidth(b []byte) int {
+	if s.widthCond == nil {
+		return runewidth.StringWidth(string(b))
+	}
+	return s.widthCond.StringWidth(string(b))
 }
 
 func concatenateBlocks(buf []byte, blocks ...[]byte) []byte {
@@ -446,8 +1243,12 @@ func concatenateBlocks(buf []byte, blocks ...[]byte) []byte {
 	return buf
 }
 
+// fillBar renders the bar. If countdown is set, remaining amount is filled,
+// instead of the current one. Completed bar is filled with completedFill,
+// or blanked with empty rune if it's nil.
 func fillBar(total, current int64, width int,
-	fmtBytes, fmtFill fmtByteSegments, rf *refill) []byte {
+	fmtBytes, fmtFill fmtByteSegments, rf *refill, countdown bool,
+	completedFill []byte) []byte {
 	if width < 2 || total <= 0 {
 		return []byte{}
 	}
@@ -457,11 +1258,20 @@ func fillBar(total, current int64, width int,
 
 	buf := make([]byte, 0, width)
 
-	// When we get to 100% don't leave bar droppings
-	if current >= total {
+	if countdown {
+		current = total - current
+		if current < 0 {
+			current = 0
+		}
+	} else if current >= total {
+		// When we get to 100% don't leave bar droppings
 		barWidth += 2
+		glyph := fmtBytes[rEmpty]
+		if completedFill != nil {
+			glyph = completedFill
+		}
 		for i := 0; i < barWidth; i++ {
-			buf = append(buf, fmtBytes[rEmpty]...)
+			buf = append(buf, glyph...)
 		}
 		return buf
 	}
@@ -508,8 +1318,92 @@ func fillBar(total, current int64, width int,
 	return buf
 }
 
+// fillMarquee fills band of 1/5 of bar's width, bouncing between bar's ends
+// as pos advances. If done, the whole bar is filled.
+func fillMarquee(width int, fmtBytes fmtByteSegments, pos int, done bool) []byte {
+	if width < 2 {
+		return []byte{}
+	}
+
+	// bar width without leftEnd and rightEnd runes
+	barWidth := width - 2
+
+	band := barWidth / 5
+	if band < 1 {
+		band = 1
+	}
+	if done {
+		band = barWidth
+	}
+	var offset int
+	if span := barWidth - band; span > 0 {
+		offset = pos % (2 * span)
+		if offset > span {
+			offset = 2*span - offset
+		}
+	}
+
+	buf := make([]byte, 0, width)
+	buf = append(buf, fmtBytes[rLeft]...)
+	for i := 0; i < barWidth; i++ {
+		if i >= offset && i < offset+band {
+			buf = append(buf, fmtBytes[rFill]...)
+		} else {
+			buf = append(buf, fmtBytes[rEmpty]...)
+		}
+	}
+	buf = append(buf, fmtBytes[rRight]...)
+
+	return buf
+}
+
+// fillSegments renders the bar, where each of segs is filled independently
+func fillSegments(total, current int64, width int,
+	fmtBytes fmtByteSegments, segs []Segment) []byte {
+	if width < 2 || total <= 0 {
+		return []byte{}
+	}
+
+	// bar width without leftEnd and rightEnd runes
+	barWidth := width - 2
+
+	buf := make([]byte, 0, width)
+
+	// When we get to 100% don't leave bar droppings
+	if current >= total {
+		barWidth += 2
+		for i := 0; i < barWidth; i++ {
+			buf = append(buf, fmtBytes[rEmpty]...)
+		}
+		return buf
+	}
+
+	filled := make([]bool, barWidth)
+	for _, seg := range segs {
+		from, _ := decor.CalcPercentage(total, seg.Start, barWidth, 0)
+		till, _ := decor.CalcPercentage(total, seg.Start+seg.Current, barWidth, 0)
+		for i := from; i < till && i < barWidth; i++ {
+			filled[i] = true
+		}
+	}
+
+	buf = append(buf, fmtBytes[rLeft]...)
+	for _, f := range filled {
+		if f {
+			buf = append(buf, fmtBytes[rFill]...)
+		} else {
+			buf = append(buf, fmtBytes[rEmpty]...)
+		}
+	}
+	buf = append(buf, fmtBytes[rRight]...)
+
+	return buf
+}
+
 func newStatistics(s *state) *decor.Statistics {
 	beg, cur := s.getDataETA()
+	// while paused, times are shifted, so time doesn't pass for decorators
+	paused := s.pausedFor()
 
 	return &decor.Statistics{
 		ID:          s.id,
@@ -517,33 +1411,48 @@ func newStatistics(s *state) *decor.Statistics {
 		Aborted:     s.aborted,
 		Total:       s.total,
 		Current:     s.current,
-		StartTime:   s.startTime,
-		TimeElapsed: time.Since(s.startTime),
+		StartTime:   s.startTime.Add(paused),
+		TimeElapsed: time.Since(s.startTime) - paused,
 
 		RollCurrent:   cur,
-		RollStartTime: beg,
+		RollStartTime: beg.Add(paused),
+		RecentRates:   s.getRecentRates(),
+		Attempt:       s.attempt,
+		MaxAttempts:   s.maxAttempts,
+		Phase:         s.phase,
+		Mark:          s.mark,
+		SeqIndex:      s.seqIndex,
+		SeqTotal:      s.seqTotal,
+		TailETA:       s.tailETA,
+		TailETASet:    !s.tailETATime.IsZero(),
+		EwmaRate:      s.ewmaRate,
+		MaxSpeed:      s.maxSpeed,
+		Current2:      s.current2,
+		Samples:       s.samples,
+		WantSamples:   s.wantSamples,
+		WidthCond:     s.widthCond,
+		RefreshRate:   s.refreshRate,
 	}
 }
 
 func fmtRunesToByteSegments(format []rune) fmtByteSegments {
 	segments := make(fmtByteSegments, len(format))
+	// single backing array for all segments
+	buf := make([]byte, 0, len(format)*utf8.UTFMax)
 	for i, r := range format {
-		buf := make([]byte, utf8.RuneLen(r))
-		utf8.EncodeRune(buf, r)
-		segments[i] = buf
+		start := len(buf)
+		buf = append(buf, make([]byte, utf8.RuneLen(r))...)
+		utf8.EncodeRune(buf[start:], r)
+		segments[i] = buf[start:len(buf):len(buf)]
 	}
 	return segments
 }
 
-func getSpinner() func() byte {
-	chars := []byte(`-\|/`)
-	repeat := len(chars) - 1
-	index := repeat
-	return func() byte {
-		if index == repeat {
-			index = -1
-		}
-		index++
-		return chars[index]
-	}
+// scratchPool holds *[]byte scratch buffers, used by draw
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		return new([]byte)
+	},
 }
+
+var spinnerChars = []byte(`-\|/`)
@@ -1,6 +1,11 @@
 package mpb
 
-import "github.com/james-antill/mpb/decor"
+import (
+	"time"
+
+	"github.com/james-antill/mpb/decor"
+	"github.com/mattn/go-runewidth"
+)
 
 // BarOption is a function option which changes the default behavior of a bar,
 // if passed to p.AddBar(int64, ...BarOption)
@@ -18,6 +23,21 @@ func PrependDecorators(prependers ...decor.DecoratorFunc) BarOption {
 	}
 }
 
+// WithCompletionAppend appends decorators, which render blanks while the bar
+// is running, and appear once it has completed, like final size of a file.
+// They're still called on every render, so width sync works as usual.
+func WithCompletionAppend(appenders ...decor.DecoratorFunc) BarOption {
+	return func(bs *state) {
+		for _, f := range appenders {
+			bs.appendFuncs = append(bs.appendFuncs, decor.ShowIf(f, completed))
+		}
+	}
+}
+
+func completed(s *decor.Statistics) bool {
+	return s.Completed
+}
+
 func BarTrimLeft() BarOption {
 	return func(bs *state) {
 		bs.trimLeftSpace = true
@@ -37,15 +57,129 @@ func BarTrim() BarOption {
 	}
 }
 
+// WithFixedWidth makes bar to be rendered at its configured width, regardless of
+// terminal width. Could be useful when output isn't a terminal, for example a
+// log file. Note that on narrow terminals, such bar will wrap.
+func WithFixedWidth() BarOption {
+	return func(bs *state) {
+		bs.fixedWidth = true
+	}
+}
+
+// WithCountdown makes bar to start full and empty as progress goes, i.e. the
+// remaining amount (total - current) is filled. See also
+// decor.PercentageRemaining.
+func WithCountdown() BarOption {
+	return func(bs *state) {
+		bs.countdown = true
+	}
+}
+
+// WithSmoothETATail makes ETA to never increase, once progress is above 95%,
+// so it doesn't oscillate near completion.
+func WithSmoothETATail() BarOption {
+	return func(bs *state) {
+		bs.smoothETATail = true
+	}
+}
+
+// WithGrowingTotal makes bar not to complete, when current reaches total, as
+// total may still grow (see Bar.AddTotal), like file count of a directory walk
+// in progress. Such bar completes by Bar.Complete or Progress.Stop only.
+func WithGrowingTotal() BarOption {
+	return func(bs *state) {
+		bs.growingTotal = true
+	}
+}
+
+// WithMaxDisplaySpeed caps speed shown by speed decorators (decor.Nsec and
+// the like) to max per second, so a large chunk read at once doesn't flash
+// absurd speed. ETA still uses true values.
+func WithMaxDisplaySpeed(max float64) BarOption {
+	return func(bs *state) {
+		bs.maxSpeed = max
+	}
+}
+
+// WithMarquee makes bar with unknown total to render a band, moving back and
+// forth across the bar's width on every refresh, instead of a spinner.
+func WithMarquee() BarOption {
+	return func(bs *state) {
+		bs.marquee = true
+	}
+}
+
+// WithSpinnerDone overrides glyph "+", rendered instead of spinner, once bar
+// with unknown total has completed. Something like "✓".
+func WithSpinnerDone(glyph string) BarOption {
+	return func(bs *state) {
+		bs.spinnerDone = glyph
+	}
+}
+
+// WithCompletedFill makes completed bar to be filled with glyph, like "█",
+// instead of being blanked.
+func WithCompletedFill(glyph string) BarOption {
+	return func(bs *state) {
+		bs.completedFill = []byte(glyph)
+	}
+}
+
+// WithMinBarWidth makes bar to keep at least n columns, when terminal is too
+// narrow to fit both bar and decorators. Bar has priority: appenders are
+// truncated first, then prependers.
+func WithMinBarWidth(n int) BarOption {
+	return func(bs *state) {
+		bs.minBarWidth = n
+	}
+}
+
+// WithSpinnerAborted overrides glyph "x", rendered instead of spinner, once
+// bar with unknown total has been aborted. Something like "✗".
+func WithSpinnerAborted(glyph string) BarOption {
+	return func(bs *state) {
+		bs.spinnerAborted = glyph
+	}
+}
+
+// WithRightAlignedAppend makes appenders to be pushed to the right edge of
+// the terminal, padding between bar and appenders, if bar is narrower than
+// terminal.
+func WithRightAlignedAppend() BarOption {
+	return func(bs *state) {
+		bs.rightAlign = true
+	}
+}
+
+// WithOpsBuffer makes bar's ops channel buffered with capacity n, so bursts of
+// bar's method calls don't block, until the bar's goroutine services them.
+// Ops are still run in the order they were queued.
+func WithOpsBuffer(n int) BarOption {
+	return func(bs *state) {
+		if n > 0 {
+			bs.opsBuffer = n
+		}
+	}
+}
+
 func BarID(id int) BarOption {
 	return func(bs *state) {
 		bs.id = id
 	}
 }
 
-// BarEtaAlpha option is a way to adjust ETA behavior.
-// You can play with it, if you're not satisfied with default behavior.
-// Default value is 0.25.
+// BarName sets name of the bar, shown by summary table (see
+// WithSummaryTable) instead of the bar's ID.
+func BarName(name string) BarOption {
+	return func(bs *state) {
+		bs.name = name
+	}
+}
+
+// BarEtaAlpha option is a way to adjust ETA behavior of decor.ETAEwma, as
+// smoothing factor of its moving average: higher value makes recent speed
+// to weight more. You can play with it, if you're not satisfied with default
+// behavior. Default value is 0.25.
 func BarEtaAlpha(a float64) BarOption {
 	return func(bs *state) {
 		bs.etaAlpha = a
@@ -58,6 +192,24 @@ func barWidth(w int) BarOption {
 	}
 }
 
+func barWidthCond(cond *runewidth.Condition) BarOption {
+	return func(bs *state) {
+		bs.widthCond = cond
+	}
+}
+
+func barRefreshRate(d time.Duration) BarOption {
+	return func(bs *state) {
+		bs.refreshRate = d
+	}
+}
+
+func withTimer(start time.Time) BarOption {
+	return func(bs *state) {
+		bs.timerStart = start
+	}
+}
+
 func barFormat(format string, fillFmt []string) BarOption {
 	return func(bs *state) {
 		bs.updateFormat(format, fillFmt)
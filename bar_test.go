@@ -3,8 +3,10 @@ package mpb_test
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -275,7 +277,370 @@ func TestBarPanics(t *testing.T) {
 	}
 }
 
+func TestBarWithOpsBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf))
+	bar := p.AddBar(100, mpb.BarTrim(), mpb.WithOpsBuffer(16))
+
+	for i := 0; i < 100; i++ {
+		bar.ResumeFill('+', int64(i+1))
+		bar.Incr(1)
+	}
+	p.Stop()
+
+	if current := bar.Current(); current != 100 {
+		t.Errorf("Expected current: %d, got: %d\n", 100, current)
+	}
+}
+
+func TestBarAfterStop(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	p.Stop()
+
+	bar := p.AddBar(100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bar.Incr(1)
+		bar.Current()
+		bar.Total()
+		bar.Complete()
+		if bar.InProgress() {
+			t.Error("Expected dummy bar not in progress")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Dummy bar methods hang")
+	}
+}
+
 func removeLastRune(bytes []byte) []byte {
 	_, size := utf8.DecodeLastRune(bytes)
 	return bytes[:len(bytes)-size]
 }
+
+func TestBarSetTotalAndPhase(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf))
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Phase(0, 0)))
+
+	bar.SetPhase("download")
+	bar.Incr(50)
+	bar.SetTotal(200)
+	if total := bar.Total(); total != 200 {
+		t.Errorf("Expected total: %d, got: %d\n", 200, total)
+	}
+	if !bar.InProgress() {
+		t.Error("Expected bar to be in progress")
+	}
+	bar.SetPhase("verify")
+	bar.Incr(150)
+	p.Stop()
+
+	if !strings.Contains(buf.String(), "verify") {
+		t.Errorf("Expected phase %q in output: %q\n", "verify", buf.String())
+	}
+}
+
+func TestBarMark(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithRefreshRate(time.Hour), mpb.WithFinalRender())
+	bar := p.AddBar(100, mpb.BarTrim(),
+		mpb.AppendDecorators(decor.SinceMark("since:%s", 0, 0, 0)))
+
+	bar.Incr(30)
+	bar.Mark()
+	bar.Incr(70)
+	bar.Complete()
+	p.Stop()
+
+	if !strings.Contains(buf.String(), "since:70") {
+		t.Errorf("Expected %q in output: %q\n", "since:70", buf.String())
+	}
+}
+
+func TestBarSetAttempt(t *testing.T) {
+	p := mpb.New(
+		mpb.Output(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithFinalRender(),
+	)
+	bar := p.AddBar(100, mpb.BarTrim(),
+		mpb.AppendDecorators(decor.Attempt("attempt %d/%d", 0, 0)))
+
+	bar.SetAttempt(2, 3)
+	time.Sleep(50 * time.Millisecond)
+	if frame := p.Frame(); len(frame) != 1 || !strings.HasSuffix(frame[0], "attempt 2/3") {
+		t.Errorf("Expected attempt in frame, got: %q\n", frame)
+	}
+	bar.Incr(100)
+	p.Stop()
+	if frame := p.Frame(); len(frame) != 1 || strings.Contains(frame[0], "attempt") {
+		t.Errorf("Expected attempt blanked on completion, got: %q\n", frame)
+	}
+}
+
+func TestBarStatisticsRefreshRate(t *testing.T) {
+	p := mpb.New(
+		mpb.Output(ioutil.Discard),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+	)
+	var got int64
+	bar := p.AddBar(100, mpb.AppendDecorators(
+		func(s *decor.Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+			atomic.StoreInt64(&got, int64(s.RefreshRate))
+			return ""
+		},
+	))
+	bar.Complete()
+	p.Stop()
+	if d := time.Duration(atomic.LoadInt64(&got)); d != time.Hour {
+		t.Errorf("Expected RefreshRate %s, got: %s\n", time.Hour, d)
+	}
+}
+
+func TestBarIncrConcurrent(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	total := 8 * 100000
+	bar := p.AddBar(int64(total))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < total/8; i++ {
+				bar.Increment()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if current := bar.Current(); current != int64(total) {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+	p.Stop()
+}
+
+func BenchmarkBarIncr(b *testing.B) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(int64(b.N))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bar.Increment()
+	}
+	b.StopTimer()
+	p.Stop()
+}
+
+func TestBarIncrPastTotal(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(100)
+
+	bar.Incr(60)
+	bar.Incr(60)
+	if current := bar.Current(); current != 100 {
+		t.Errorf("Expected current capped at: %d, got: %d\n", 100, current)
+	}
+	p.Stop()
+	if current := bar.Current(); current != 100 {
+		t.Errorf("Expected current capped at: %d, got: %d\n", 100, current)
+	}
+}
+
+func TestBarSetTotalBelowCurrent(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(100, mpb.AppendDecorators(decor.PercentageTol(0, 0, 0)))
+
+	bar.Incr(80)
+	bar.SetTotal(50)
+	if current := bar.Current(); current != 50 {
+		t.Errorf("Expected current clamped to: %d, got: %d\n", 50, current)
+	}
+	stats, _ := p.StopStats()
+	if !stats.Completed || stats.Current != 50 || stats.Total != 50 {
+		t.Errorf("Want: completed 50/50, got: %v %d/%d\n",
+			stats.Completed, stats.Current, stats.Total)
+	}
+}
+
+func TestBarAddTotal(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithRefreshRate(10*time.Millisecond))
+	bar := p.AddBar(0, mpb.WithGrowingTotal())
+
+	for i := 0; i < 5; i++ {
+		bar.AddTotal(10)
+		bar.Incr(10)
+		// give renders a chance to complete the bar prematurely
+		time.Sleep(30 * time.Millisecond)
+		if !bar.InProgress() {
+			t.Fatalf("Bar completed prematurely at %d/%d\n", bar.Current(), bar.Total())
+		}
+	}
+	if total := bar.Total(); total != 50 {
+		t.Errorf("Expected total: %d, got: %d\n", 50, total)
+	}
+	bar.Complete()
+	p.Stop()
+	if current := bar.Current(); current != 50 {
+		t.Errorf("Expected current: %d, got: %d\n", 50, current)
+	}
+}
+
+func TestBarSetPercent(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(200)
+
+	tests := []struct {
+		percent float64
+		want    int64
+	}{
+		{42, 84},
+		{10, 20},
+		{-5, 0},
+		{150, 200},
+	}
+	for _, test := range tests {
+		bar.SetPercent(test.percent)
+		if current := bar.Current(); current != test.want {
+			t.Errorf("SetPercent(%v): want current: %d, got: %d\n",
+				test.percent, test.want, current)
+		}
+	}
+	p.Stop()
+
+	p = mpb.New(mpb.Output(ioutil.Discard))
+	bar = p.AddBar(0)
+	bar.SetPercent(50)
+	if current := bar.Current(); current != 0 {
+		t.Errorf("Expected no-op on unknown total, got current: %d\n", current)
+	}
+	p.Stop()
+}
+
+func TestBarSpeed(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(1000)
+
+	if speed := bar.Speed(); speed != 0 {
+		t.Errorf("Expected zero speed before start, got: %v\n", speed)
+	}
+	bar.Incr(1)
+	// bar's clock starts, once increment is accounted
+	bar.Current()
+	time.Sleep(100 * time.Millisecond)
+	bar.Incr(99)
+	// 100 items in about 100ms
+	if speed := bar.Speed(); speed < 500 || speed > 1100 {
+		t.Errorf("Expected speed about 1000/s, got: %v\n", speed)
+	}
+	p.Stop()
+}
+
+func TestBarSetTotalSpinner(t *testing.T) {
+	p := mpb.New(
+		mpb.Output(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithFinalRender(),
+	)
+	bar := p.AddBar(100, mpb.BarTrim(), mpb.WithSpinnerDone("+"))
+	bar.Incr(50)
+	time.Sleep(50 * time.Millisecond)
+	if frame := p.Frame(); len(frame) != 1 || utf8.RuneCountInString(frame[0]) <= 3 {
+		t.Fatalf("Expected fill bar, got: %q\n", frame)
+	}
+
+	bar.SetTotal(0)
+	time.Sleep(50 * time.Millisecond)
+	if frame := p.Frame(); len(frame) != 1 || utf8.RuneCountInString(frame[0]) != 3 {
+		t.Fatalf("Expected spinner, got: %q\n", frame)
+	}
+	if !bar.InProgress() {
+		t.Fatal("Expected spinner bar in progress")
+	}
+
+	bar.Complete()
+	p.Stop()
+	if frame := p.Frame(); len(frame) != 1 || !strings.Contains(frame[0], "+") {
+		t.Errorf("Expected completed spinner, got: %q\n", frame)
+	}
+}
+
+func TestBarRenderStats(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithRefreshRate(10*time.Millisecond))
+	bar := p.AddBar(100)
+
+	time.Sleep(100 * time.Millisecond)
+	count, last := bar.RenderStats()
+	if count == 0 || time.Since(last) > time.Second {
+		t.Errorf("Expected recent renders, got: %d, %v\n", count, last)
+	}
+	bar.Complete()
+	p.Stop()
+	if after, _ := bar.RenderStats(); after < count {
+		t.Errorf("Expected render count not to decrease: %d < %d\n", after, count)
+	}
+}
+
+func TestBarRenderStatsSkipped(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithRefreshRate(10*time.Millisecond))
+	// more bars than any terminal is high, so the first ones are skipped
+	bars := make([]*mpb.Bar, 300)
+	for i := range bars {
+		bars[i] = p.AddBar(100)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if count, _ := bars[0].RenderStats(); count != 0 {
+		t.Errorf("Expected skipped bar not to be counted, got: %d\n", count)
+	}
+	if count, _ := bars[len(bars)-1].RenderStats(); count == 0 {
+		t.Errorf("Expected visible bar to be counted, got: %d\n", count)
+	}
+	p.Stop()
+}
+
+func TestBarAdoptFrom(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	failed := p.AddBar(100)
+	failed.Incr(40)
+	failed.Abort()
+	before := failed.Speed()
+
+	retry := p.AddBar(100)
+	retry.AdoptFrom(failed)
+	if current := retry.Current(); current != 40 {
+		t.Errorf("Expected current: %d, got: %d\n", 40, current)
+	}
+	if speed := retry.Speed(); speed <= 0 || speed > before {
+		t.Errorf("Expected speed history adopted, got: %v (was %v)\n", speed, before)
+	}
+	retry.Incr(60)
+	p.Stop()
+	if current := retry.Current(); current != 100 {
+		t.Errorf("Expected current: %d, got: %d\n", 100, current)
+	}
+}
+
+func TestBarIncr2(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithRefreshRate(time.Hour), mpb.WithFinalRender())
+	bar := p.AddBar(100, mpb.BarTrim(),
+		mpb.AppendDecorators(decor.AvgSize("avg:%s", 0, 0, 0)))
+
+	bar.Incr(60)
+	bar.Incr2(3)
+	bar.Incr(40)
+	bar.Incr2(2)
+	bar.Complete()
+	p.Stop()
+
+	if !strings.Contains(buf.String(), "avg:20.00") {
+		t.Errorf("Expected %q in output: %q\n", "avg:20.00", buf.String())
+	}
+}
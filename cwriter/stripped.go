@@ -0,0 +1,77 @@
+package cwriter
+
+import (
+	"bytes"
+	"io"
+)
+
+// EscapeParser tracks escape sequences, like "\x1b[31m", in a stream of
+// bytes, fed to it one by one. Sequences may be split across writes, as its
+// state is kept between them.
+type EscapeParser struct {
+	inEsc bool
+	inCSI bool
+}
+
+// Visible advances parser by c, and reports whether c is a part of visible
+// text, rather than of an escape sequence.
+func (p *EscapeParser) Visible(c byte) bool {
+	switch {
+	case p.inCSI:
+		// CSI sequence ends with a byte in 0x40-0x7E range
+		if c >= 0x40 && c <= 0x7e {
+			p.inCSI = false
+		}
+	case p.inEsc:
+		p.inEsc = false
+		p.inCSI = c == '['
+	case c == ESC:
+		p.inEsc = true
+	default:
+		return true
+	}
+	return false
+}
+
+// StripEscapes returns b without escape sequences. If there're none, b itself
+// is returned.
+func StripEscapes(b []byte) []byte {
+	if bytes.IndexByte(b, ESC) < 0 {
+		return b
+	}
+	var p EscapeParser
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if p.Visible(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// strippedWriter drops escape sequences and carriage returns, keeping
+// parsing state between writes, as a sequence may be split across them.
+type strippedWriter struct {
+	out    io.Writer
+	parser EscapeParser
+}
+
+// NewStripped returns a writer, which filters cursor control (any escape)
+// sequences and carriage returns out of everything written to w, so output of
+// the full render path is readable, if append heavy, text in a log file.
+func NewStripped(w io.Writer) io.Writer {
+	return &strippedWriter{out: w}
+}
+
+func (w *strippedWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(p))
+	for _, c := range p {
+		if w.parser.Visible(c) && c != '\r' {
+			buf = append(buf, c)
+		}
+	}
+	if _, err := w.out.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -2,6 +2,7 @@ package cwriter
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 )
 
@@ -15,6 +16,14 @@ type Writer struct {
 
 	buf       bytes.Buffer
 	lineCount int
+	// if true, previous lines are never cleared, output is append only
+	plain bool
+	// written above buf by the next Flush, and never cleared afterwards
+	persistent bytes.Buffer
+	// if not zero, at most that many lines are cleared and redrawn
+	maxLines int
+	// scratch buffer of Flush, so a frame is a single write
+	frame bytes.Buffer
 }
 
 // New returns a new Writer with defaults
@@ -24,20 +33,96 @@ func New(w io.Writer) *Writer {
 	}
 }
 
-// Flush flushes the underlying buffer
+// Flush flushes the underlying buffer. Clear sequence of the previous
+// lines, persistent buffer and w's buffer are written at once.
 func (w *Writer) Flush() error {
 	// Do nothing if buffer is empty
-	if w.buf.Len() == 0 {
+	if w.buf.Len() == 0 && w.persistent.Len() == 0 {
 		return nil
 	}
-	w.clearLines()
-	w.lineCount = bytes.Count(w.buf.Bytes(), []byte("\n"))
-	_, err := w.out.Write(w.buf.Bytes())
+	w.frame.Reset()
+	// lines of the previous flush, which have scrolled off the screen,
+	// can't be redrawn, so the same number of leading lines isn't written
+	var scrolled int
+	if !w.plain {
+		scrolled = w.lineCount - w.clearCount()
+		w.clearLines(&w.frame)
+	}
+	w.frame.Write(w.persistent.Bytes())
+	w.persistent.Reset()
+	buf := w.buf.Bytes()
+	w.lineCount = bytes.Count(buf, []byte("\n"))
+	for ; scrolled > 0; scrolled-- {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+		buf = buf[i+1:]
+	}
+	w.frame.Write(buf)
 	w.buf.Reset()
+	_, err := w.out.Write(w.frame.Bytes())
 	return err
 }
 
+// clearCount returns number of lines to be cleared by clearLines
+func (w *Writer) clearCount() int {
+	if w.maxLines > 0 && w.lineCount > w.maxLines {
+		return w.maxLines
+	}
+	return w.lineCount
+}
+
 // Write save the contents of b to its buffers. The only errors returned are ones encountered while writing to the underlying buffer.
 func (w *Writer) Write(b []byte) (n int, err error) {
 	return w.buf.Write(b)
 }
+
+// Persist saves b to be written by the next Flush, above the contents of
+// w's buffer. Unlike the latter, b isn't cleared by subsequent flushes, so
+// it's kept in the output, like a log line above the bars.
+func (w *Writer) Persist(b []byte) {
+	w.persistent.Write(b)
+}
+
+// Bell writes terminal bell directly to the underlying writer, so it neither
+// waits for the next Flush, nor affects lines cleared by it.
+func (w *Writer) Bell() error {
+	_, err := w.out.Write([]byte("\a"))
+	return err
+}
+
+// SetScrollRegion sets terminal's scroll region (DECSTBM) to lines from top
+// to bottom, 1 based, and moves cursor to its top. It's written directly to
+// the underlying writer.
+func (w *Writer) SetScrollRegion(top, bottom int) error {
+	_, err := fmt.Fprintf(w.out, "%c[%d;%dr%c[%d;1H", ESC, top, bottom, ESC, top)
+	return err
+}
+
+// ResetScrollRegion restores full screen scroll region.
+func (w *Writer) ResetScrollRegion() error {
+	_, err := fmt.Fprintf(w.out, "%c[r", ESC)
+	return err
+}
+
+// SetPlain turns off cursor control sequences, so every flush is appended
+// below the previous one. Useful for terminals, which don't support them.
+func (w *Writer) SetPlain(plain bool) {
+	w.plain = plain
+}
+
+// SetMaxLines makes w to clear at most n lines, written by the previous
+// Flush, like the number of lines visible on the terminal, minus one for the
+// cursor. Lines above them have scrolled off the screen and can't be
+// redrawn, so they are neither cleared, nor written again. Zero means no
+// limit.
+func (w *Writer) SetMaxLines(n int) {
+	w.maxLines = n
+}
+
+// ResetLineCount makes w forget lines written by the last Flush, so they
+// aren't cleared by the next one.
+func (w *Writer) ResetLineCount() {
+	w.lineCount = 0
+}
@@ -3,6 +3,7 @@
 package cwriter
 
 import (
+	"bytes"
 	"fmt"
 	"syscall"
 	"unsafe"
@@ -14,9 +15,10 @@ var (
 	clearCursorAndLine = cursorUp + clearLine
 )
 
-func (w *Writer) clearLines() {
-	for i := 0; i < w.lineCount; i++ {
-		fmt.Fprint(w.out, clearCursorAndLine)
+// clearLines appends clear sequence of the previous lines to buf
+func (w *Writer) clearLines(buf *bytes.Buffer) {
+	for i := w.clearCount(); i > 0; i-- {
+		buf.WriteString(clearCursorAndLine)
 	}
 }
 
@@ -34,3 +34,50 @@ func TestWriterPosix(t *testing.T) {
 		}
 	}
 }
+
+// TestWriterMaxLines checks, that lines scrolled off the screen are neither
+// cleared, nor written again.
+func TestWriterMaxLines(t *testing.T) {
+	out := new(bytes.Buffer)
+	w := cwriter.New(out)
+	w.SetMaxLines(2)
+
+	w.Write([]byte("a\nb\nc\n"))
+	w.Flush()
+	out.Reset()
+	w.Write([]byte("A\nB\nC\n"))
+	w.Flush()
+	want := clearSequence + clearSequence + "B\nC\n"
+	if out.String() != want {
+		t.Fatalf("want %q, got %q", want, out.String())
+	}
+}
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+// TestWriterSingleWrite checks, that clear sequence, persistent lines and
+// the frame are written at once.
+func TestWriterSingleWrite(t *testing.T) {
+	out := new(countingWriter)
+	w := cwriter.New(out)
+	for i := 0; i < 3; i++ {
+		w.Persist([]byte("log\n"))
+		fmt.Fprintln(w, "foo")
+		w.Flush()
+	}
+	if out.writes != 3 {
+		t.Fatalf("want %d writes, got %d", 3, out.writes)
+	}
+	want := "log\nfoo\n" + clearSequence + "log\nfoo\n" + clearSequence + "log\nfoo\n"
+	if out.String() != want {
+		t.Fatalf("want %q, got %q", want, out.String())
+	}
+}
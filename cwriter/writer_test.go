@@ -18,3 +18,81 @@ func TestWriter(t *testing.T) {
 		t.Fatalf("want %q, got %q", want, b.String())
 	}
 }
+
+func TestWriterPlain(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := New(b)
+	w.SetPlain(true)
+	for i := 0; i < 2; i++ {
+		fmt.Fprintln(w, "foo")
+		w.Flush()
+	}
+	want := "foo\nfoo\n"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+}
+
+func TestWriterPersist(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := New(b)
+	fmt.Fprintln(w, "bar")
+	w.Flush()
+	b.Reset()
+
+	w.Persist([]byte("log\n"))
+	fmt.Fprintln(w, "bar")
+	w.Flush()
+	// previous "bar" is cleared, log is written above the new one
+	want := "log\nbar\n"
+	if !bytes.HasSuffix(b.Bytes(), []byte(want)) {
+		t.Fatalf("want suffix %q, got %q", want, b.String())
+	}
+	if w.lineCount != 1 {
+		t.Fatalf("want lineCount 1, got %d", w.lineCount)
+	}
+}
+
+func TestWriterScrollRegion(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := New(b)
+	w.SetScrollRegion(3, 24)
+	w.ResetScrollRegion()
+	want := "\x1b[3;24r\x1b[3;1H\x1b[r"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+}
+
+func TestStripped(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := New(NewStripped(b))
+	for i := 0; i < 2; i++ {
+		fmt.Fprintln(w, "foo")
+		w.Flush()
+	}
+	want := "foo\nfoo\n"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+}
+
+func TestStrippedSplitSequence(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := NewStripped(b)
+	for _, s := range []string{"a\x1b", "[2", "Kb\r\x1b[1Ac"} {
+		fmt.Fprint(w, s)
+	}
+	want := "abc"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+}
+
+func TestStripEscapes(t *testing.T) {
+	got := StripEscapes([]byte("\x1b[31mred\x1b[0m plain"))
+	want := "red plain"
+	if string(got) != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
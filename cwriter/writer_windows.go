@@ -3,6 +3,7 @@
 package cwriter
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"syscall"
@@ -50,12 +51,15 @@ type FdWriter interface {
 	Fd() uintptr
 }
 
-func (w *Writer) clearLines() {
+// clearLines appends clear sequence of the previous lines to buf, unless w
+// is a console, which is cleared directly
+func (w *Writer) clearLines(buf *bytes.Buffer) {
+	n := w.clearCount()
 	f, ok := w.out.(FdWriter)
 	if ok && !isatty.IsTerminal(f.Fd()) {
-		for i := 0; i < w.lineCount; i++ {
-			fmt.Fprintf(w.out, "%c[%dA", ESC, 1) // move the cursor up
-			fmt.Fprintf(w.out, "%c[2K\r", ESC)   // clear the line
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(buf, "%c[%dA", ESC, 1) // move the cursor up
+			fmt.Fprintf(buf, "%c[2K\r", ESC)   // clear the line
 		}
 		return
 	}
@@ -63,7 +67,7 @@ func (w *Writer) clearLines() {
 	var info consoleScreenBufferInfo
 	procGetConsoleScreenBufferInfo.Call(fd, uintptr(unsafe.Pointer(&info)))
 
-	for i := 0; i < w.lineCount; i++ {
+	for i := 0; i < n; i++ {
 		// move the cursor up
 		info.cursorPosition.y--
 		procSetConsoleCursorPosition.Call(fd, uintptr(*(*int32)(unsafe.Pointer(&info.cursorPosition))))
@@ -0,0 +1,57 @@
+package decor
+
+// Builder provides fluent way to set up minWidth and conf params of the
+// decorators, which accept them. For example:
+//
+//	decor.NewBuilder().WidthSync().RightAlign().Build(decor.ETA)
+//
+// is the same as decor.ETA(0, decor.DwidthSync|decor.DidentRight).
+type Builder struct {
+	minWidth int
+	conf     byte
+}
+
+// NewBuilder returns a new Builder with zero minWidth and conf
+func NewBuilder() *Builder {
+	return new(Builder)
+}
+
+// WidthSync sets DwidthSync bit
+func (b *Builder) WidthSync() *Builder {
+	b.conf |= DwidthSync
+	return b
+}
+
+// RightAlign sets DidentRight bit
+func (b *Builder) RightAlign() *Builder {
+	b.conf |= DidentRight
+	return b
+}
+
+// ExtraSpace sets DextraSpace bit
+func (b *Builder) ExtraSpace() *Builder {
+	b.conf |= DextraSpace
+	return b
+}
+
+// Color sets Dcolor bit
+func (b *Builder) Color() *Builder {
+	b.conf |= Dcolor
+	return b
+}
+
+// MinWidth sets minWidth
+func (b *Builder) MinWidth(n int) *Builder {
+	b.minWidth = n
+	return b
+}
+
+// Build calls kind with configured minWidth and conf. Decorators with extra
+// params can be wrapped in a closure:
+//
+//	b.Build(func(w int, c byte) decor.DecoratorFunc {
+//		return decor.Counters("%s / %s", decor.Unit_KiB, w, c)
+//	})
+func (b *Builder) Build(kind func(minWidth int, conf byte) DecoratorFunc) DecoratorFunc {
+	return kind(b.minWidth, b.conf)
+}
@@ -2,8 +2,13 @@ package decor
 
 import (
 	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/james-antill/mpb/cwriter"
 	runewidth "github.com/mattn/go-runewidth"
 )
 
@@ -22,6 +27,10 @@ const (
 	// otherwise to the left.
 	DextraSpace
 
+	// Dcolor colors output with ANSI colors, if decorator supports it, like
+	// SpeedDelta.
+	Dcolor
+
 	// DSyncSpace is shortcut for DwidthSync|DextraSpace
 	DSyncSpace = DwidthSync | DextraSpace
 )
@@ -39,10 +48,69 @@ type Statistics struct {
 	TimePerItemEstimate time.Duration
 	RollStartTime       time.Time
 	RollCurrent         int64
+	// RecentRates are per second rates of the recent rolling average slots,
+	// from the oldest to the newest one.
+	RecentRates []int64
+	// Attempt and MaxAttempts are set by Bar.SetAttempt
+	Attempt     int
+	MaxAttempts int
+	// Phase is set by Bar.SetPhase
+	Phase string
+	// Mark is Current, recorded by the last Bar.Mark call
+	Mark int64
+	// SeqIndex and SeqTotal are set by Bar.SetSequence
+	SeqIndex int
+	SeqTotal int
+	// TailETA is monotonic ETA near completion, valid if TailETASet, see
+	// mpb.WithSmoothETATail
+	TailETA    time.Duration
+	TailETASet bool
+	// EwmaRate is exponentially weighted moving average of items per
+	// second, see mpb.BarEtaAlpha
+	EwmaRate float64
+	// MaxSpeed, if > 0, caps displayed speed, see mpb.WithMaxDisplaySpeed
+	MaxSpeed float64
+	// Current2 is secondary counter, see mpb.Bar.Incr2
+	Current2 int64
+	// Samples of recent progress, from the oldest to the newest one, see
+	// SpeedWindow. Bar keeps them only for window requested by WantSamples.
+	Samples []Sample
+	// WantSamples, if not nil, requests bar to keep Samples for the last
+	// window of time, from now on
+	WantSamples func(window time.Duration)
+	// WidthCond, if not nil, measures width of decorators, synced by
+	// DwidthSync, see mpb.WithAmbiguousWidth
+	WidthCond *runewidth.Condition
+	// RefreshRate is refresh rate of the container, see mpb.WithRefreshRate
+	RefreshRate time.Duration
+}
+
+// StringWidth returns width of str in terminal's columns, measured by
+// WidthCond, if it's set.
+func (s *Statistics) StringWidth(str string) int {
+	if s == nil || s.WidthCond == nil {
+		return runewidth.StringWidth(str)
+	}
+	return s.WidthCond.StringWidth(str)
+}
+
+// padWidth converts width in columns, which str is to be padded to, to
+// width of fmt's padding, which counts runes.
+func (s *Statistics) padWidth(str string, width int) int {
+	return width + utf8.RuneCountInString(str) - s.StringWidth(str)
+}
+
+// Sample is Current value of the bar at Time
+type Sample struct {
+	Time    time.Time
+	Current int64
 }
 
 // Eta moving-average ETA estimator
 func (s *Statistics) Eta() time.Duration {
+	if s.TailETASet {
+		return s.TailETA
+	}
 	timeElapsed := time.Since(s.RollStartTime)
 
 	nsec := float64(s.RollCurrent) / timeElapsed.Seconds()
@@ -50,6 +118,31 @@ func (s *Statistics) Eta() time.Duration {
 	return eta
 }
 
+// Speed returns moving-average speed in items per second, the one rendered by
+// Nsec decorator, before MaxSpeed cap.
+func (s *Statistics) Speed() float64 {
+	if s.Current <= 0 {
+		return 0
+	}
+	return float64(s.RollCurrent) / time.Since(s.RollStartTime).Seconds()
+}
+
+// Percentage returns progress in 0-100 range, 0 if Total is unknown. It never
+// rounds up, so it's 100 only when Current has reached Total.
+func (s *Statistics) Percentage() float64 {
+	if s.Total <= 0 {
+		return 0
+	}
+	if s.Current >= s.Total {
+		return 100
+	}
+	pc := 100 * float64(s.Current) / float64(s.Total)
+	if pc >= 100 {
+		pc = math.Nextafter(100, 0)
+	}
+	return pc
+}
+
 // DecoratorFunc is a function that can be prepended and appended to the progress bar
 type DecoratorFunc func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string
 
@@ -67,6 +160,14 @@ func StaticName(name string, minWidth int, conf byte) DecoratorFunc {
 	return DynamicName(nameFn, minWidth, conf)
 }
 
+// Static always renders text as is, without width sync. Could be used for
+// separators, like " | ", and labels.
+func Static(text string) DecoratorFunc {
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		return text
+	}
+}
+
 // DynamicName to be used, when there is a plan to change the name once or
 // several times during progress rendering process. If there're more than one
 // bar, and you'd like to synchronize column width, conf param should have
@@ -80,12 +181,12 @@ func DynamicName(nameFn func(*Statistics) string, minWidth int, conf byte) Decor
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
 		name := nameFn(s)
 		if (conf & DwidthSync) != 0 {
-			myWidth <- runewidth.StringWidth(name)
+			myWidth <- s.StringWidth(name)
 			max := <-maxWidth
 			if (conf & DextraSpace) != 0 {
 				max++
 			}
-			return fmt.Sprintf(fmt.Sprintf(format, max), name)
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(name, max)), name)
 		}
 		return fmt.Sprintf(fmt.Sprintf(format, minWidth), name)
 	}
@@ -111,224 +212,1200 @@ func Counters(pairFormat string, unit Units, minWidth int, conf byte) DecoratorF
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
 		str := CountersString(s, pairFormat, unit)
 		if (conf & DwidthSync) != 0 {
-			myWidth <- runewidth.StringWidth(str)
+			myWidth <- s.StringWidth(str)
 			max := <-maxWidth
 			if (conf & DextraSpace) != 0 {
 				max++
 			}
-			return fmt.Sprintf(fmt.Sprintf(format, max), str)
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
 		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
 	}
 }
 
-// Nsec provides basic Num/sec decorator.
-// Accepts string, something like "%s/s" to be used in
-// fmt.Sprintf(nsecformat, current) and one of (Unit_KiB/Unit_kB)
-// constant. If there're more than one bar, and you'd like to synchronize column
-// width, conf param should have DwidthSync bit set.
-func NsecString(s *Statistics, nsecformat string, unit Units) string {
-	var nsec float64
-	if s.Current > 0 {
-		timeElapsed := time.Since(s.RollStartTime)
-		nsec = float64(s.RollCurrent) / timeElapsed.Seconds()
-	}
-	current := FormatF(nsec).To(unit)
-	str := fmt.Sprintf(nsecformat, current)
+// CountersNoTotal provides current only counter decorator, for bars with
+// unknown total. Accepts format string, something like "%s" to be used in
+// fmt.Sprintf(format, current) and one of (Unit_KiB/Unit_kB) constant.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func CountersNoTotalString(s *Statistics, format string, unit Units) string {
+	current := Format(s.Current).To(unit)
+	str := fmt.Sprintf(format, current)
 	return str
 }
-func Nsec(nsecformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
+func CountersNoTotal(cformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
 	format := "%%"
 	if (conf & DidentRight) != 0 {
 		format += "-"
 	}
 	format += "%ds"
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
-		str := NsecString(s, nsecformat, unit)
+		str := CountersNoTotalString(s, cformat, unit)
 		if (conf & DwidthSync) != 0 {
-			myWidth <- runewidth.StringWidth(str)
+			myWidth <- s.StringWidth(str)
 			max := <-maxWidth
 			if (conf & DextraSpace) != 0 {
 				max++
 			}
-			return fmt.Sprintf(fmt.Sprintf(format, max), str)
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
 		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
 	}
 }
 
-func smallDurationString(d time.Duration) string {
-
-	switch {
-	case d >= 13*7*24*time.Hour:
-		return "≥13w"
-	case d >= 7*24*time.Hour:
-		hours := int(d.Round(time.Hour).Hours())
-		days := hours / 24
-		weeks := days / 7
-		days %= 7
-		if days > 0 {
-			return fmt.Sprintf("%dw%dd", weeks, days)
-		} else {
-			return fmt.Sprintf("%dw", weeks)
+// Processed provides decorator of amount processed so far, formatted like
+// current side of Counters, without any format string.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func ProcessedString(s *Statistics, unit Units) string {
+	return Format(s.Current).To(unit).String()
+}
+func Processed(unit Units, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := ProcessedString(s, unit)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
-	case d >= 24*time.Hour:
-		hours := int(d.Round(time.Hour).Hours())
-		days := hours / 24
-		hours %= 24
-		if hours > 0 {
-			return fmt.Sprintf("%dd%dh", days, hours)
-		} else {
-			return fmt.Sprintf("%dd", days)
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Remaining provides remaining counter decorator, useful with countdown bars.
+// Accepts pairFormat string, something like "%s / %s" to be used in
+// fmt.Sprintf(pairFormat, remaining, total) and one of (Unit_KiB/Unit_kB)
+// constant. If there're more than one bar, and you'd like to synchronize column
+// width, conf param should have DwidthSync bit set.
+func RemainingString(s *Statistics, pairFormat string, unit Units) string {
+	rem := s.Total - s.Current
+	if rem < 0 {
+		rem = 0
+	}
+	return fmt.Sprintf(pairFormat, Format(rem).To(unit), Format(s.Total).To(unit))
+}
+func Remaining(pairFormat string, unit Units, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := RemainingString(s, pairFormat, unit)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
-	case d >= 60*time.Minute:
-		mins := int(d.Round(time.Minute).Minutes())
-		hours := mins / 60
-        mins %= 60
-		if mins > 0 {
-			return fmt.Sprintf("%dh%dm", hours, mins)
-		} else {
-			return fmt.Sprintf("%dh", hours)
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// RemainingItems provides decorator of remaining item count, in words, like
+// "3 files remaining" or "1 file remaining", picking singular or plural form.
+// Renders blanks, if total is unknown. If there're more than one bar, and
+// you'd like to synchronize column width, conf param should have DwidthSync
+// bit set.
+func RemainingItemsString(s *Statistics, singular, plural string) string {
+	if s.Total <= 0 {
+		return ""
+	}
+	rem := s.Total - s.Current
+	if rem < 0 {
+		rem = 0
+	}
+	word := plural
+	if rem == 1 {
+		word = singular
+	}
+	return fmt.Sprintf("%d %s remaining", rem, word)
+}
+func RemainingItems(singular, plural string, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := RemainingItemsString(s, singular, plural)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
-	case d >= 60*time.Second:
-		secs := int(d.Round(time.Second).Seconds())
-		mins := secs / 60
-        secs %= 60
-		if secs > 0 {
-			return fmt.Sprintf("%dm%ds", mins, secs)
-		} else {
-			return fmt.Sprintf("%dm", mins)
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// CountRaw provides current count decorator, without any unit scaling.
+// Accepts format string, something like "%s records" to be used in
+// fmt.Sprintf(format, current). If group is true, thousands are separated by
+// commas, like "1,234,567". If there're more than one bar, and you'd like to
+// synchronize column width, conf param should have DwidthSync bit set.
+func CountRawString(s *Statistics, format string, group bool) string {
+	current := fmt.Sprint(s.Current)
+	if group {
+		current = groupDigits(s.Current)
+	}
+	str := fmt.Sprintf(format, current)
+	return str
+}
+func CountRaw(cformat string, group bool, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := CountRawString(s, cformat, group)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
-	case d >= 8*time.Second:
-		return d.Round(time.Second).String()
-	default:
-		return d.Round(100 * time.Millisecond).String()
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
 	}
 }
 
-// ETA provides exponential-weighted-moving-average ETA decorator, shows the
-// elapsed time after the progress has finished.
+// TotalOnly provides total counter decorator. Accepts format string,
+// something like "of %s" to be used in fmt.Sprintf(format, total) and one of
+// (Unit_KiB/Unit_kB) constant. Renders blanks, if total is unknown.
 // If there're more than one bar, and you'd like to synchronize column width,
 // conf param should have DwidthSync bit set.
-func ETAString(s *Statistics) string {
-	var dur time.Duration
-	if s.Current == s.Total {
-		return smallDurationString(s.TimeElapsed)
-	} else {
-		dur = s.Eta()
-	}
-	var str string
-	secs := int(dur.Seconds()) % 60
-	if s.RollCurrent == 0 {
-		return "∞:??"
-	} else if dur.Hours() > 999*24 {
-		str = "∞"
-	} else if dur.Hours() > 36 { // In theory this could be higher, but human UI
-		d := dur.Round(time.Hour*24).Hours() / 24
-		str = fmt.Sprintf("~%dd", int(d))
-	} else if dur.Minutes() > 59 {
-		h := dur.Round(time.Hour).Hours()
-		str = fmt.Sprintf("~%dh", int(h))
-	} else if dur.Seconds() < 3 {
-		str = "~2s"
-	} else {
-		str = fmt.Sprintf("%d:%02d", int(dur.Minutes()), secs)
+func TotalOnlyString(s *Statistics, format string, unit Units) string {
+	if s.Total <= 0 {
+		return ""
 	}
+	total := Format(s.Total).To(unit)
+	str := fmt.Sprintf(format, total)
 	return str
 }
-func ETA(minWidth int, conf byte) DecoratorFunc {
+func TotalOnly(tformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
 	format := "%%"
 	if (conf & DidentRight) != 0 {
 		format += "-"
 	}
 	format += "%ds"
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
-		str := ETAString(s)
+		str := TotalOnlyString(s, tformat, unit)
 		if (conf & DwidthSync) != 0 {
-			myWidth <- runewidth.StringWidth(str)
+			myWidth <- s.StringWidth(str)
 			max := <-maxWidth
 			if (conf & DextraSpace) != 0 {
 				max++
 			}
-			return fmt.Sprintf(fmt.Sprintf(format, max), str)
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
 		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
 	}
 }
 
-// Elapsed provides elapsed time decorator.
+// AvgSize provides average item size decorator, Current divided by
+// secondary counter Current2 (see mpb.Bar.Incr2). Accepts format string,
+// something like "avg %s/file" to be used in fmt.Sprintf(format, avg) and one
+// of (Unit_KiB/Unit_kB) constant. Renders blanks, until there are items.
 // If there're more than one bar, and you'd like to synchronize column width,
 // conf param should have DwidthSync bit set.
-func ElapsedString(s *Statistics) string {
-	str := fmt.Sprint(time.Duration(s.TimeElapsed.Seconds()) * time.Second)
+func AvgSizeString(s *Statistics, format string, unit Units) string {
+	if s.Current2 <= 0 {
+		return ""
+	}
+	avg := FormatF(float64(s.Current) / float64(s.Current2)).To(unit)
+	str := fmt.Sprintf(format, avg)
 	return str
 }
-func Elapsed(minWidth int, conf byte) DecoratorFunc {
+func AvgSize(aformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
 	format := "%%"
 	if (conf & DidentRight) != 0 {
 		format += "-"
 	}
 	format += "%ds"
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
-		str := ElapsedString(s)
+		str := AvgSizeString(s, aformat, unit)
 		if (conf & DwidthSync) != 0 {
-			myWidth <- runewidth.StringWidth(str)
+			myWidth <- s.StringWidth(str)
 			max := <-maxWidth
 			if (conf & DextraSpace) != 0 {
 				max++
 			}
-			return fmt.Sprintf(fmt.Sprintf(format, max), str)
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
 		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
 	}
 }
 
-// Percentage provides percentage decorator.
+// SinceMark provides counter decorator, which shows amount since the last
+// Bar.Mark call. Accepts format string, something like "%s" to be used in
+// fmt.Sprintf(format, current-mark) and one of (Unit_KiB/Unit_kB) constant.
 // If there're more than one bar, and you'd like to synchronize column width,
 // conf param should have DwidthSync bit set.
-func PercentageString(s *Statistics) string {
-	str := "   "
-	if s.Current > 0 && s.Current < s.Total {
-		// Don't round up to 100%
-		pc := (100 * s.Current) / s.Total
-		str = fmt.Sprintf("%2d%%", pc)
-	}
+func SinceMarkString(s *Statistics, format string, unit Units) string {
+	since := Format(s.Current - s.Mark).To(unit)
+	str := fmt.Sprintf(format, since)
 	return str
 }
-func Percentage(minWidth int, conf byte) DecoratorFunc {
+func SinceMark(mformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
 	format := "%%"
 	if (conf & DidentRight) != 0 {
 		format += "-"
 	}
 	format += "%ds"
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
-		str := PercentageString(s)
+		str := SinceMarkString(s, mformat, unit)
 		if (conf & DwidthSync) != 0 {
-			myWidth <- runewidth.StringWidth(str)
+			myWidth <- s.StringWidth(str)
 			max := <-maxWidth
 			if (conf & DextraSpace) != 0 {
 				max++
 			}
-			return fmt.Sprintf(fmt.Sprintf(format, max), str)
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
 		}
 		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
 	}
 }
 
-func DefDataPreBar(unit Units) DecoratorFunc {
+// ShowAfter wraps base decorator, so it renders blanks until the bar has been
+// running for d. Base decorator is still called, so it keeps its width and
+// participates in width sync. Useful to hide early wild ETA estimates.
+func ShowAfter(base DecoratorFunc, d time.Duration) DecoratorFunc {
+	return ShowIf(base, func(s *Statistics) bool {
+		return s.TimeElapsed >= d
+	})
+}
+
+// ShowIf wraps base decorator, so it renders blanks, unless pred returns
+// true. Base decorator is still called, so it keeps its width and
+// participates in width sync. Unlike Conditional, width is kept while hidden.
+func ShowIf(base DecoratorFunc, pred func(*Statistics) bool) DecoratorFunc {
 	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
-		str := NsecString(s, "%s/s ", unit)
-		str += CountersString(s, "%s%.0s", unit)
-		pc := PercentageString(s)
-		if pc != "" {
-			str += " "
-			str += pc
+		str := base(s, myWidth, maxWidth)
+		if !pred(s) {
+			// escapes, like colors, take no columns
+			return strings.Repeat(" ", s.StringWidth(string(cwriter.StripEscapes([]byte(str)))))
 		}
-
 		return str
 	}
 }
 
+// Conditional provides decorator, which renders ifTrue, if pred returns true,
+// or ifFalse otherwise, evaluated on each render. Width sync channels are
+// passed to the picked decorator only, so both of them should have the same
+// DwidthSync bit set, as different bars may pick different ones.
+func Conditional(pred func(*Statistics) bool, ifTrue, ifFalse DecoratorFunc) DecoratorFunc {
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		if pred(s) {
+			return ifTrue(s, myWidth, maxWidth)
+		}
+		return ifFalse(s, myWidth, maxWidth)
+	}
+}
+
+// autoColors is palette of AutoColor, ANSI foreground colors from red to cyan
+var autoColors = [...]int{31, 32, 33, 34, 35, 36}
+
+// AutoColor wraps base decorator, coloring its output with ANSI color picked
+// by the bar's ID, so neighbour bars are easier to tell apart. Base decorator
+// gets the width sync channels, so widths exclude escape codes.
+func AutoColor(base DecoratorFunc) DecoratorFunc {
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := base(s, myWidth, maxWidth)
+		i := s.ID % len(autoColors)
+		if i < 0 {
+			i += len(autoColors)
+		}
+		return fmt.Sprintf("\x1b[%dm%s\x1b[0m", autoColors[i], str)
+	}
+}
+
+// SpeedWindow provides speed decorator, averaged over the last window of
+// time (up to 30s), regardless of rolling average, used by Nsec. Samples are
+// kept from its first render on, so speed is 0 until then. Accepts
+// format string, something like "%s/s" to be used in fmt.Sprintf(format,
+// speed) and one of (Unit_KiB/Unit_kB) constant. If there're more than one
+// bar, and you'd like to synchronize column width, conf param should have
+// DwidthSync bit set.
+func SpeedWindowString(s *Statistics, window time.Duration, speedFormat string, unit Units) string {
+	if s.WantSamples != nil {
+		s.WantSamples(window)
+	}
+	var speed float64
+	now := time.Now()
+	cutoff := now.Add(-window)
+	for _, sample := range s.Samples {
+		if sample.Time.Before(cutoff) {
+			continue
+		}
+		// the oldest sample within window is the base
+		if dur := now.Sub(sample.Time); dur > 0 {
+			speed = float64(s.Current-sample.Current) / dur.Seconds()
+		}
+		break
+	}
+	if s.MaxSpeed > 0 && speed > s.MaxSpeed {
+		speed = s.MaxSpeed
+	}
+	return fmt.Sprintf(speedFormat, FormatF(speed).To(unit))
+}
+func SpeedWindow(window time.Duration, speedFormat string, unit Units, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := SpeedWindowString(s, window, speedFormat, unit)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Nsec provides basic Num/sec decorator.
+// Accepts string, something like "%s/s" to be used in
+// fmt.Sprintf(nsecformat, current) and one of (Unit_KiB/Unit_kB)
+// constant. If there're more than one bar, and you'd like to synchronize column
+// width, conf param should have DwidthSync bit set.
+func NsecString(s *Statistics, nsecformat string, unit Units) string {
+	current := FormatF(rollSpeed(s)).To(unit)
+	str := fmt.Sprintf(nsecformat, current)
+	return str
+}
+func Nsec(nsecformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := NsecString(s, nsecformat, unit)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// SpeedAligned is like Nsec, but speed is formatted with decimal points
+// aligned (see formatter.AlignDecimal), so a column of speeds isn't ragged.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func SpeedAlignedString(s *Statistics, nsecformat string, unit Units) string {
+	current := FormatF(rollSpeed(s)).To(unit).AlignDecimal()
+	str := fmt.Sprintf(nsecformat, current)
+	return str
+}
+func SpeedAligned(nsecformat string, unit Units, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := SpeedAlignedString(s, nsecformat, unit)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// SpeedDelta provides decorator, which shows signed percentage difference of
+// rolling speed from target speed, like "-12%" or "+5%". For fixed units
+// (Unit_MiB, Unit_MB, etc.) target is in that unit per second, otherwise it's
+// in items per second. Renders blanks, until there is some progress. If conf
+// param has Dcolor bit set, difference is red below target, green otherwise.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func SpeedDeltaString(s *Statistics, target float64, unit Units) string {
+	target *= unitSize(unit)
+	if target <= 0 || s.Current <= 0 {
+		return ""
+	}
+	delta := 100 * (rollSpeed(s) - target) / target
+	return fmt.Sprintf("%+d%%", int(math.Round(delta)))
+}
+func SpeedDelta(target float64, unit Units, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	// colored after padding, so escape codes don't count to the width
+	paint := func(str, out string) string {
+		if (conf&Dcolor) == 0 || str == "" {
+			return out
+		}
+		color := 32
+		if str[0] == '-' {
+			color = 31
+		}
+		return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, out)
+	}
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := SpeedDeltaString(s, target, unit)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return paint(str, fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str))
+		}
+		return paint(str, fmt.Sprintf(fmt.Sprintf(format, minWidth), str))
+	}
+}
+
+// rollSpeed returns items per second, measured over rolling window, capped
+// by s.MaxSpeed
+func rollSpeed(s *Statistics) float64 {
+	speed := s.Speed()
+	if s.MaxSpeed > 0 && speed > s.MaxSpeed {
+		speed = s.MaxSpeed
+	}
+	return speed
+}
+
+// SpeedAuto provides speed decorator, with IEC unit (b/KiB/MiB/GiB) picked
+// automatically per value. Accepts string, something like "%s/s" to be used in
+// fmt.Sprintf(format, speed).
+func SpeedAuto(format string, minWidth int, conf byte) DecoratorFunc {
+	return Nsec(format, Unit_KiB, minWidth, conf)
+}
+
+// SpeedAutoSI is like SpeedAuto, but with SI unit (b/KB/MB/GB).
+func SpeedAutoSI(format string, minWidth int, conf byte) DecoratorFunc {
+	return Nsec(format, Unit_kB, minWidth, conf)
+}
+
+func smallDurationString(d time.Duration) string {
+
+	switch {
+	case d >= 13*7*24*time.Hour:
+		return "≥13w"
+	case d >= 7*24*time.Hour:
+		hours := int(d.Round(time.Hour).Hours())
+		days := hours / 24
+		weeks := days / 7
+		days %= 7
+		if days > 0 {
+			return fmt.Sprintf("%dw%dd", weeks, days)
+		} else {
+			return fmt.Sprintf("%dw", weeks)
+		}
+	case d >= 24*time.Hour:
+		hours := int(d.Round(time.Hour).Hours())
+		days := hours / 24
+		hours %= 24
+		if hours > 0 {
+			return fmt.Sprintf("%dd%dh", days, hours)
+		} else {
+			return fmt.Sprintf("%dd", days)
+		}
+	case d >= 60*time.Minute:
+		mins := int(d.Round(time.Minute).Minutes())
+		hours := mins / 60
+        mins %= 60
+		if mins > 0 {
+			return fmt.Sprintf("%dh%dm", hours, mins)
+		} else {
+			return fmt.Sprintf("%dh", hours)
+		}
+	case d >= 60*time.Second:
+		secs := int(d.Round(time.Second).Seconds())
+		mins := secs / 60
+        secs %= 60
+		if secs > 0 {
+			return fmt.Sprintf("%dm%ds", mins, secs)
+		} else {
+			return fmt.Sprintf("%dm", mins)
+		}
+	case d >= 8*time.Second:
+		return d.Round(time.Second).String()
+	default:
+		return d.Round(100 * time.Millisecond).String()
+	}
+}
+
+// ETA provides exponential-weighted-moving-average ETA decorator, shows the
+// elapsed time after the bar has completed, even if it hasn't reached total.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func ETAString(s *Statistics) string {
+	if s.Completed {
+		return smallDurationString(s.TimeElapsed)
+	}
+	if s.RollCurrent == 0 {
+		return "∞:??"
+	}
+	return etaDurationString(s.Eta())
+}
+func ETA(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := ETAString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// etaDurationString formats remaining time of ETA decorators, coarser as it
+// grows, like "~3h" or "~2d".
+func etaDurationString(dur time.Duration) string {
+	var str string
+	secs := int(dur.Seconds()) % 60
+	if dur.Hours() > 999*24 {
+		str = "∞"
+	} else if dur.Hours() > 36 { // In theory this could be higher, but human UI
+		d := dur.Round(time.Hour*24).Hours() / 24
+		str = fmt.Sprintf("~%dd", int(d))
+	} else if dur.Minutes() > 59 {
+		h := dur.Round(time.Hour).Hours()
+		str = fmt.Sprintf("~%dh", int(h))
+	} else if dur.Seconds() < 3 {
+		str = "~2s"
+	} else {
+		str = fmt.Sprintf("%d:%02d", int(dur.Minutes()), secs)
+	}
+	return str
+}
+
+// ETAEwma provides ETA decorator, estimated by exponentially weighted moving
+// average of speed, which is smoothed per bar by mpb.BarEtaAlpha option.
+// Shows the elapsed time after the bar has completed.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func ETAEwmaString(s *Statistics) string {
+	if s.Completed {
+		return smallDurationString(s.TimeElapsed)
+	}
+	if s.EwmaRate <= 0 {
+		return "∞:??"
+	}
+	dur := time.Duration(float64(s.Total-s.Current)/s.EwmaRate) * time.Second
+	return etaDurationString(dur)
+}
+func ETAEwma(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := ETAEwmaString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// ETAClock provides ETA decorator, which shows wall clock time of estimated
+// completion, formatted with layout (see time.Time.Format), something like
+// "15:04". Shows "--:--" when there is no data to estimate yet.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func ETAClockString(s *Statistics, layout string) string {
+	if s.RollCurrent == 0 {
+		return "--:--"
+	}
+	dur := s.Eta()
+	if dur < 0 || dur.Hours() > 999*24 {
+		return "--:--"
+	}
+	return time.Now().Add(dur).Format(layout)
+}
+func ETAClock(layout string, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := ETAClockString(s, layout)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Elapsed provides elapsed time decorator.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func ElapsedString(s *Statistics) string {
+	str := fmt.Sprint(time.Duration(s.TimeElapsed.Seconds()) * time.Second)
+	return str
+}
+func Elapsed(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := ElapsedString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// ElapsedClock provides elapsed time decorator, of fixed HH:MM:SS format,
+// like "00:01:23". Hours aren't limited to 2 digits.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func ElapsedClockString(s *Statistics) string {
+	secs := int64(s.TimeElapsed.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+func ElapsedClock(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := ElapsedClockString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Deadline provides time budget decorator, shows the percentage of time between
+// StartTime and deadline, which has been used so far. Once the deadline has
+// passed, "over budget" is shown instead.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func DeadlineString(s *Statistics, deadline time.Time) string {
+	budget := deadline.Sub(s.StartTime)
+	if budget <= 0 || s.TimeElapsed > budget {
+		return "over budget"
+	}
+	pc := int(100 * s.TimeElapsed.Seconds() / budget.Seconds())
+	return fmt.Sprintf("%2d%% of budget", pc)
+}
+func Deadline(deadline time.Time, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := DeadlineString(s, deadline)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Countdown provides decorator, which counts down to deadline by wall clock,
+// regardless of progress, like "00:45", or "1:02:03" if there's an hour or
+// more left. Once deadline has passed, "00:00" is rendered.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func CountdownString(s *Statistics, deadline time.Time) string {
+	left := time.Until(deadline)
+	if left < 0 {
+		left = 0
+	}
+	// rounded up, so "00:00" is shown only once deadline has passed
+	secs := int64((left + time.Second - 1) / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+func Countdown(deadline time.Time, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := CountdownString(s, deadline)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Percentage provides percentage decorator.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func PercentageString(s *Statistics) string {
+	str := "   "
+	if s.Current > 0 && s.Current < s.Total {
+		str = fmt.Sprintf("%2d%%", int(s.Percentage()))
+	}
+	return str
+}
+func Percentage(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := PercentageString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// PercentageTol provides percentage decorator for approximately measured
+// progress, which may never reach Total exactly. It shows 100%, once the bar
+// has completed, or is within tol percentage points of Total.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func PercentageTolString(s *Statistics, tol float64) string {
+	if s.Total <= 0 || s.Current <= 0 {
+		return "   "
+	}
+	pc := s.Percentage()
+	if s.Completed || pc >= 100-tol {
+		return "100%"
+	}
+	return fmt.Sprintf("%2d%%", int(pc))
+}
+func PercentageTol(tol float64, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := PercentageTolString(s, tol)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// PercentageShaded provides percentage decorator, which renders percentage
+// text centered over width cells of background, shaded proportionally to
+// completion, like "▓▓▓42%░░░░". Compact alternative to a separate bar.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func PercentageShadedString(s *Statistics, width int) string {
+	text := []rune(fmt.Sprintf("%d%%", int(s.Percentage())))
+	if width < len(text) {
+		width = len(text)
+	}
+	filled := int(s.Percentage() * float64(width) / 100)
+	cells := make([]rune, width)
+	for i := range cells {
+		if i < filled {
+			cells[i] = '▓'
+		} else {
+			cells[i] = '░'
+		}
+	}
+	copy(cells[(width-len(text))/2:], text)
+	return string(cells)
+}
+func PercentageShaded(width, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := PercentageShadedString(s, width)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// PercentageRemaining provides remaining percentage decorator, useful with
+// countdown bars. Rounds up, so 0% isn't shown before completion.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func PercentageRemainingString(s *Statistics) string {
+	str := "   "
+	if s.Total > 0 && s.Current < s.Total {
+		rem := s.Total - s.Current
+		pc := (100*rem + s.Total - 1) / s.Total
+		str = fmt.Sprintf("%2d%%", pc)
+	}
+	return str
+}
+func PercentageRemaining(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := PercentageRemainingString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// PercentageOfTotal provides decorator of the bar's share of externally
+// supplied grand total, like "2.3% of total" for a file of a batch. Renders
+// blanks, if total is unknown.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func PercentageOfTotalString(s *Statistics, total int64) string {
+	if total <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%% of total", 100*float64(s.Current)/float64(total))
+}
+func PercentageOfTotal(total int64, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := PercentageOfTotalString(s, total)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Spinner provides spinner decorator, which advances to the next frame once per
+// refresh interval of the container, no matter how many times it's rendered.
+// Without RefreshRate, it advances on each render instead, so each bar should
+// get its own Spinner. If frames is empty, `-\|/` frames are used. Once bar has
+// completed, "+" is rendered, or "x" if it has been aborted, same as the bar's
+// spinner defaults.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func Spinner(frames []string, conf byte) DecoratorFunc {
+	if len(frames) == 0 {
+		frames = []string{"-", "\\", "|", "/"}
+	}
+	var minWidth int
+	for _, f := range frames {
+		if w := runewidth.StringWidth(f); w > minWidth {
+			minWidth = w
+		}
+	}
+	var count uint32
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		var str string
+		switch {
+		case s != nil && s.Aborted:
+			str = "x"
+		case s != nil && s.Completed:
+			str = "+"
+		case s != nil && s.RefreshRate > 0 && s.TimeElapsed >= 0:
+			i := s.TimeElapsed / s.RefreshRate
+			str = frames[i%time.Duration(len(frames))]
+		default:
+			i := atomic.AddUint32(&count, 1) - 1
+			str = frames[i%uint32(len(frames))]
+		}
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Attempt provides retry attempt decorator. Accepts format string, something
+// like "(try %d/%d)" to be used in fmt.Sprintf(format, attempt, maxAttempts).
+// Renders blanks, if there is no attempt set, or the bar has completed.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func AttemptString(s *Statistics, format string) string {
+	if s.Attempt == 0 || s.Completed {
+		return ""
+	}
+	return fmt.Sprintf(format, s.Attempt, s.MaxAttempts)
+}
+func Attempt(aformat string, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := AttemptString(s, aformat)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Sequence provides decorator, which shows position of the bar's item within
+// a known set. Accepts format string, something like "[%d/%d]" to be used in
+// fmt.Sprintf(format, index, total). Renders blanks, if there is no sequence
+// set. If there're more than one bar, and you'd like to synchronize column
+// width, conf param should have DwidthSync bit set.
+func SequenceString(s *Statistics, format string) string {
+	if s.SeqTotal == 0 {
+		return ""
+	}
+	return fmt.Sprintf(format, s.SeqIndex, s.SeqTotal)
+}
+func Sequence(sformat string, minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := SequenceString(s, sformat)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+// Phase provides decorator, which shows label of the bar's current phase, set
+// by Bar.SetPhase. If there're more than one bar, and you'd like to
+// synchronize column width, conf param should have DwidthSync bit set.
+func PhaseString(s *Statistics) string {
+	return s.Phase
+}
+func Phase(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := PhaseString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+var sparkGlyphs = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline provides sparkline decorator of the recent rates, something like
+// "▁▂▅▇▆". Rates are scaled relative to the highest one.
+// If there're more than one bar, and you'd like to synchronize column width,
+// conf param should have DwidthSync bit set.
+func SparklineString(s *Statistics) string {
+	var max int64
+	for _, r := range s.RecentRates {
+		if r > max {
+			max = r
+		}
+	}
+	line := make([]rune, len(s.RecentRates))
+	for i, r := range s.RecentRates {
+		var g int
+		if max > 0 {
+			g = int(r * int64(len(sparkGlyphs)-1) / max)
+		}
+		line[i] = sparkGlyphs[g]
+	}
+	return string(line)
+}
+func Sparkline(minWidth int, conf byte) DecoratorFunc {
+	format := "%%"
+	if (conf & DidentRight) != 0 {
+		format += "-"
+	}
+	format += "%ds"
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := SparklineString(s)
+		if (conf & DwidthSync) != 0 {
+			myWidth <- s.StringWidth(str)
+			max := <-maxWidth
+			if (conf & DextraSpace) != 0 {
+				max++
+			}
+			return fmt.Sprintf(fmt.Sprintf(format, s.padWidth(str, max)), str)
+		}
+		return fmt.Sprintf(fmt.Sprintf(format, minWidth), str)
+	}
+}
+
+func DefDataPreBar(unit Units) DecoratorFunc {
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := NsecString(s, "%s/s ", unit)
+		str += CountersString(s, "%s%.0s", unit)
+		pc := PercentageString(s)
+		if pc != "" {
+			str += " "
+			str += pc
+		}
+
+		return str
+	}
+}
+
+// DefDataNoTotal is DefDataPreBar counterpart for bars with unknown total,
+// renders something like "12.3MB at 4.1MB/s".
+func DefDataNoTotal(unit Units) DecoratorFunc {
+	return func(s *Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		str := CountersNoTotalString(s, "%s", unit)
+		str += NsecString(s, " at %s/s", unit)
+		return str
+	}
+}
+
+// FillWidths splits progress of stat over width cells into full cells and
+// a partial one, to build custom fills with. Ramp is the number of glyphs for
+// the partial cell, ordered from the least to the most filled one (like
+// "▏▎▍▌▋▊▉"). Returned partialIndex is index into that ramp, or -1 if there
+// is no partial cell. With ramp 0, full is rounded to the nearest cell and
+// partialIndex is always -1. Current above Total counts as Total.
+func FillWidths(stat *Statistics, width, ramp int) (full int, partialIndex int) {
+	if stat.Total <= 0 || width <= 0 {
+		return 0, -1
+	}
+	current := stat.Current
+	if current > stat.Total {
+		current = stat.Total
+	}
+	full, foff := CalcPercentage(stat.Total, current, width, ramp)
+	// foff is 1-based in ramp + 1 slots, 0 being an empty partial cell
+	return full, foff - 1
+}
+
+// CalcPercentage returns number of completed cells of width, and if fill > 0,
+// 1-based index of fill glyph for the partial cell, 0 if none. See FillWidths
+// for friendlier API.
 func CalcPercentage(total, current int64, width, fill int) (int, int) {
 	if total == 0 || current > total {
 		return 0, 0
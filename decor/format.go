@@ -25,6 +25,21 @@ const (
 	Unit_kB
 	// Unit_k Kibibyte = 1000
 	Unit_k
+
+	// Following units don't auto scale, value is always shown in the unit.
+
+	// Unit_MiB Mebibyte = 1024 KiB
+	Unit_MiB
+	// Unit_GiB Gibibyte = 1024 MiB
+	Unit_GiB
+	// Unit_TiB Tebibyte = 1024 GiB
+	Unit_TiB
+	// Unit_MB Megabyte = 1000 kB
+	Unit_MB
+	// Unit_GB Gigabyte = 1000 MB
+	Unit_GB
+	// Unit_TB Terabyte = 1000 GB
+	Unit_TB
 )
 
 // Unit_Kb Kilobyte = 1000 b
@@ -36,23 +51,27 @@ const Unit_KB = Unit_kB
 type Units uint
 
 func Format(i int64) *formatter {
-	return &formatter{n: i}
+	return &formatter{n: i, precision: -1}
 }
 
 func FormatF(i float64) *formatterF {
-	return &formatterF{n: i}
+	return &formatterF{n: i, precision: -1}
 }
 
 type formatter struct {
-	n     int64
-	unit  Units
-	width int
+	n            int64
+	unit         Units
+	width        int
+	precision    int
+	alignDecimal bool
 }
 
 type formatterF struct {
-	n     float64
-	unit  Units
-	width int
+	n            float64
+	unit         Units
+	width        int
+	precision    int
+	alignDecimal bool
 }
 
 func (f *formatter) To(unit Units) *formatter {
@@ -65,14 +84,30 @@ func (f *formatter) Width(width int) *formatter {
 	return f
 }
 
+// Precision forces n decimals of scaled value, regardless of its magnitude.
+// Has no effect without unit.
+func (f *formatter) Precision(n int) *formatter {
+	f.precision = n
+	return f
+}
+
+// AlignDecimal pads integer part and fixes fractional part of scaled value,
+// so decimal points of values in a column line up, like "  9.9KB" and
+// "999.0KB". Has no effect without unit.
+func (f *formatter) AlignDecimal() *formatter {
+	f.alignDecimal = true
+	return f
+}
+
 func (f *formatter) String() string {
 	switch f.unit {
-	case Unit_KiB:
-		return formatKiB(f.n)
-	case Unit_kB:
-		return formatKB(f.n)
-	case Unit_k:
-		return formatK(f.n)
+	case Unit_KiB, Unit_kB, Unit_k,
+		Unit_MiB, Unit_GiB, Unit_TiB, Unit_MB, Unit_GB, Unit_TB:
+		ff := FormatF(float64(f.n)).To(f.unit).Precision(f.precision)
+		if f.alignDecimal {
+			ff.AlignDecimal()
+		}
+		return ff.String()
 	default:
 		return fmt.Sprintf(fmt.Sprintf("%%%dd", f.width), f.n)
 	}
@@ -88,16 +123,102 @@ func (f *formatterF) Width(width int) *formatterF {
 	return f
 }
 
+// Precision forces n decimals of scaled value, regardless of its magnitude.
+// Without unit, it overrides default precision of 2.
+func (f *formatterF) Precision(n int) *formatterF {
+	f.precision = n
+	return f
+}
+
+// AlignDecimal pads integer part and fixes fractional part of scaled value,
+// so decimal points of values in a column line up, like "  9.9KB" and
+// "999.0KB". Has no effect without unit.
+func (f *formatterF) AlignDecimal() *formatterF {
+	f.alignDecimal = true
+	return f
+}
+
 func (f *formatterF) String() string {
+	var n float64
+	var ext string
 	switch f.unit {
 	case Unit_KiB:
-		return formatFKiB(f.n)
+		n, ext = scaleKiB(f.n)
 	case Unit_kB:
-		return formatFKB(f.n)
+		n, ext = scaleKB(f.n)
 	case Unit_k:
-		return formatFK(f.n)
+		n, ext = scaleK(f.n)
+	case Unit_MiB:
+		n, ext = f.n/MiB, "MiB"
+	case Unit_GiB:
+		n, ext = f.n/GiB, "GiB"
+	case Unit_TiB:
+		n, ext = f.n/TiB, "TiB"
+	case Unit_MB:
+		n, ext = f.n/MB, "MB"
+	case Unit_GB:
+		n, ext = f.n/GB, "GB"
+	case Unit_TB:
+		n, ext = f.n/TB, "TB"
 	default:
-		return fmt.Sprintf(fmt.Sprintf("%%%d.2f", f.width), f.n)
+		precision := f.precision
+		if precision < 0 {
+			precision = 2
+		}
+		return fmt.Sprintf(fmt.Sprintf("%%%d.%df", f.width, precision), f.n)
+	}
+	if f.alignDecimal {
+		precision := f.precision
+		if precision < 0 {
+			precision = 1
+		}
+		// scaled IEC value can be up to 1023.x
+		intDigits := 3
+		if f.unit == Unit_KiB {
+			intDigits = 4
+		}
+		return fmt.Sprintf("%*.*f%s", intDigits+1+precision, precision, n, ext)
+	}
+	if f.precision >= 0 {
+		return fmt.Sprintf("%.*f%s", f.precision, n, ext)
+	}
+	return fmtSprint(n, ext)
+}
+
+// groupDigits formats n with thousands separated by commas, like "1,234,567"
+func groupDigits(n int64) string {
+	str := fmt.Sprint(n)
+	sign := ""
+	if n < 0 {
+		sign, str = "-", str[1:]
+	}
+	var buf []byte
+	for i := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, str[i])
+	}
+	return sign + string(buf)
+}
+
+// unitSize returns size of fixed unit, or 1 for auto scaled ones
+func unitSize(unit Units) float64 {
+	switch unit {
+	case Unit_MiB:
+		return MiB
+	case Unit_GiB:
+		return GiB
+	case Unit_TiB:
+		return TiB
+	case Unit_MB:
+		return MB
+	case Unit_GB:
+		return GB
+	case Unit_TB:
+		return TB
+	default:
+		return 1
 	}
 }
 
@@ -121,7 +242,8 @@ func fmtSprint(f float64, ext string) string {
 	return fmt.Sprintf("%.1f%s", f, ext)
 }
 
-func formatFKiB(f float64) string {
+// scaleKiB returns f scaled to the best fitting IEC unit, and its extension
+func scaleKiB(f float64) (float64, string) {
 	ext := "b  "
 	switch {
 	case f >= TiB:
@@ -137,13 +259,11 @@ func formatFKiB(f float64) string {
 		f /= KiB
 		ext = "KiB"
 	}
-	return fmtSprint(f, ext)
-}
-func formatKiB(i int64) string {
-	return formatFKiB(float64(i))
+	return f, ext
 }
 
-func formatFKB(f float64) string {
+// scaleKB returns f scaled to the best fitting SI unit, and its extension
+func scaleKB(f float64) (float64, string) {
 	ext := "b "
 	switch {
 	case f >= TB:
@@ -159,13 +279,11 @@ func formatFKB(f float64) string {
 		f /= KB
 		ext = "KB"
 	}
-	return fmtSprint(f, ext)
-}
-func formatKB(i int64) string {
-	return formatFKB(float64(i))
+	return f, ext
 }
 
-func formatFK(f float64) string {
+// scaleK returns f scaled to the best fitting SI multiplier, and its suffix
+func scaleK(f float64) (float64, string) {
 	ext := " "
 	switch {
 	case f >= TB:
@@ -181,8 +299,5 @@ func formatFK(f float64) string {
 		f /= KB
 		ext = "K"
 	}
-	return fmtSprint(f, ext)
-}
-func formatK(i int64) string {
-	return formatFK(float64(i))
+	return f, ext
 }
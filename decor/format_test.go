@@ -41,3 +41,59 @@ func TestFormatToBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatPrecision(t *testing.T) {
+	inputs := []struct {
+		v int64
+		e string
+	}{
+		{v: 3*decor.MiB + 140*decor.KiB, e: "3.14MiB"},
+		{v: 20 * decor.MiB, e: "20.00MiB"},
+	}
+
+	for _, input := range inputs {
+		actual := decor.Format(input.v).To(decor.Unit_KiB).Precision(2).String()
+		if actual != input.e {
+			t.Errorf("Expected %q but found %q", input.e, actual)
+		}
+	}
+}
+
+func TestFormatToFixedUnit(t *testing.T) {
+	inputs := []struct {
+		v    int64
+		unit decor.Units
+		e    string
+	}{
+		{v: decor.MiB / 2, unit: decor.Unit_MiB, e: "0.5MiB"},
+		{v: 1200 * decor.MiB, unit: decor.Unit_MiB, e: "1200MiB"},
+		{v: 3 * decor.GB, unit: decor.Unit_MB, e: "3000MB"},
+	}
+
+	for _, input := range inputs {
+		actual := decor.Format(input.v).To(input.unit).String()
+		if actual != input.e {
+			t.Errorf("Expected %q but found %q", input.e, actual)
+		}
+	}
+}
+
+func TestFormatAlignDecimal(t *testing.T) {
+	inputs := []struct {
+		v    int64
+		unit decor.Units
+		e    string
+	}{
+		{v: 9900, unit: decor.Unit_kB, e: "  9.9KB"},
+		{v: 999 * decor.KB, unit: decor.Unit_kB, e: "999.0KB"},
+		{v: 5 * decor.KiB, unit: decor.Unit_KiB, e: "   5.0KiB"},
+		{v: 1000 * decor.KiB, unit: decor.Unit_KiB, e: "1000.0KiB"},
+	}
+
+	for _, input := range inputs {
+		actual := decor.Format(input.v).To(input.unit).AlignDecimal().String()
+		if actual != input.e {
+			t.Errorf("Expected %q but found %q", input.e, actual)
+		}
+	}
+}
@@ -1,12 +1,14 @@
 package mpb_test
 
 import (
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	. "github.com/james-antill/mpb"
 	"github.com/james-antill/mpb/decor"
+	"github.com/mattn/go-runewidth"
 )
 
 func TestStaticName(t *testing.T) {
@@ -40,6 +42,628 @@ func TestStaticName(t *testing.T) {
 	}
 }
 
+func TestDeadline(t *testing.T) {
+	start := time.Now()
+	deadline := start.Add(10 * time.Second)
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{elapsed: 0, want: " 0% of budget"},
+		{elapsed: 6 * time.Second, want: "60% of budget"},
+		{elapsed: 11 * time.Second, want: "over budget"},
+	}
+
+	fn := decor.Deadline(deadline, 0, 0)
+	for _, test := range tests {
+		stat := &decor.Statistics{StartTime: start, TimeElapsed: test.elapsed}
+		got := fn(stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestElapsedClock(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{elapsed: 0, want: "00:00:00"},
+		{elapsed: 83 * time.Second, want: "00:01:23"},
+		{elapsed: time.Hour + 2*time.Minute + 3500*time.Millisecond, want: "01:02:03"},
+		{elapsed: 100 * time.Hour, want: "100:00:00"},
+	}
+	fn := decor.ElapsedClock(0, 0)
+	for _, test := range tests {
+		got := fn(&decor.Statistics{TimeElapsed: test.elapsed}, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestSpinner(t *testing.T) {
+	fn := decor.Spinner([]string{"a", "bb"}, 0)
+	for _, want := range []string{" a", "bb", " a"} {
+		got := fn(nil, nil, nil)
+		if got != want {
+			t.Errorf("Want: %q, Got: %q\n", want, got)
+		}
+	}
+}
+
+func TestSpinnerRefreshRate(t *testing.T) {
+	fn := decor.Spinner(nil, 0)
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{0, "-"},
+		{99 * time.Millisecond, "-"},
+		{250 * time.Millisecond, "|"},
+		{450 * time.Millisecond, "-"},
+	}
+	for _, test := range tests {
+		stat := &decor.Statistics{RefreshRate: 100 * time.Millisecond, TimeElapsed: test.elapsed}
+		// extra renders within the same interval mustn't advance it
+		for i := 0; i < 3; i++ {
+			if got := fn(stat, nil, nil); got != test.want {
+				t.Errorf("%s: Want: %q, Got: %q\n", test.elapsed, test.want, got)
+			}
+		}
+	}
+}
+
+func TestSpinnerCompleted(t *testing.T) {
+	fn := decor.Spinner(nil, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{}, "-"},
+		{&decor.Statistics{Completed: true}, "+"},
+		{&decor.Statistics{Completed: true, Aborted: true}, "x"},
+	}
+	for _, test := range tests {
+		if got := fn(test.stat, nil, nil); got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	stat := &decor.Statistics{RecentRates: []int64{0, 10, 35, 70, 60}}
+	want := "▁▂▄█▇"
+	got := decor.Sparkline(0, 0)(stat, nil, nil)
+	if got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestSpeedDelta(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{}, ""},
+		{&decor.Statistics{Current: 88, RollCurrent: 88, RollStartTime: now.Add(-time.Second)}, "-12%"},
+		{&decor.Statistics{Current: 105, RollCurrent: 105, RollStartTime: now.Add(-time.Second)}, "+5%"},
+	}
+	fn := decor.SpeedDelta(100, 0, 0, 0)
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestSpeedDeltaColor(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{}, ""},
+		{&decor.Statistics{Current: 88, RollCurrent: 88, RollStartTime: now.Add(-time.Second)}, "\x1b[31m-12%\x1b[0m"},
+		{&decor.Statistics{Current: 105, RollCurrent: 105, RollStartTime: now.Add(-time.Second)}, "\x1b[32m+5%\x1b[0m"},
+	}
+	fn := decor.SpeedDelta(100, 0, 0, decor.Dcolor)
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	fn := decor.Attempt("(try %d/%d)", 11, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{}, "           "},
+		{&decor.Statistics{Attempt: 2, MaxAttempts: 3}, "  (try 2/3)"},
+		{&decor.Statistics{Attempt: 2, MaxAttempts: 3, Completed: true}, "           "},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	fn := decor.Sequence("[%d/%d]", 7, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{}, "       "},
+		{&decor.Statistics{SeqIndex: 3, SeqTotal: 16}, " [3/16]"},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestPercentageTol(t *testing.T) {
+	fn := decor.PercentageTol(0.5, 0, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{Total: 1000}, "   "},
+		{&decor.Statistics{Total: 1000, Current: 990}, "99%"},
+		{&decor.Statistics{Total: 1000, Current: 996}, "100%"},
+		{&decor.Statistics{Total: 1000, Current: 900, Completed: true}, "100%"},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestCountRaw(t *testing.T) {
+	tests := []struct {
+		current int64
+		group   bool
+		want    string
+	}{
+		{current: 0, group: true, want: "0 records"},
+		{current: 999, group: true, want: "999 records"},
+		{current: 1000, group: true, want: "1,000 records"},
+		{current: 1234567, group: true, want: "1,234,567 records"},
+		{current: 1234567, group: false, want: "1234567 records"},
+	}
+	for _, test := range tests {
+		fn := decor.CountRaw("%s records", test.group, 0, 0)
+		got := fn(&decor.Statistics{Current: test.current}, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestPercentageShaded(t *testing.T) {
+	fn := decor.PercentageShaded(10, 0, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{Total: 100}, "░░░░0%░░░░"},
+		{&decor.Statistics{Total: 100, Current: 42}, "▓▓▓42%░░░░"},
+		{&decor.Statistics{Total: 100, Current: 100}, "▓▓▓100%▓▓▓"},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestETACompleted(t *testing.T) {
+	// completed by Complete(), without reaching total
+	stat := &decor.Statistics{
+		Total:         100,
+		Current:       40,
+		Completed:     true,
+		TimeElapsed:   5 * time.Second,
+		RollCurrent:   40,
+		RollStartTime: time.Now().Add(-5 * time.Second),
+	}
+	want := "5s"
+	if got := decor.ETA(0, 0)(stat, nil, nil); got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestETAEwma(t *testing.T) {
+	fn := decor.ETAEwma(0, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{Total: 100, Current: 10}, "∞:??"},
+		{&decor.Statistics{Total: 100, Current: 10, EwmaRate: 1}, "1:30"},
+		{&decor.Statistics{Total: 100, Current: 100, Completed: true, TimeElapsed: 5 * time.Second}, "5s"},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestTotalOnly(t *testing.T) {
+	fn := decor.TotalOnly("of %s", decor.Unit_kB, 0, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{Current: 10}, ""},
+		{&decor.Statistics{Total: 2900 * decor.MB, Current: 10}, "of 2.9GB"},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestFillWidths(t *testing.T) {
+	tests := []struct {
+		stat        *decor.Statistics
+		width, ramp int
+		full, index int
+	}{
+		{&decor.Statistics{Total: 0, Current: 10}, 10, 8, 0, -1},
+		{&decor.Statistics{Total: 100, Current: 0}, 10, 8, 0, -1},
+		{&decor.Statistics{Total: 100, Current: 50}, 10, 8, 5, -1},
+		{&decor.Statistics{Total: 100, Current: 55}, 10, 8, 5, 3},
+		{&decor.Statistics{Total: 100, Current: 55}, 10, 0, 6, -1},
+		{&decor.Statistics{Total: 100, Current: 150}, 10, 8, 10, -1},
+	}
+	for _, test := range tests {
+		full, index := decor.FillWidths(test.stat, test.width, test.ramp)
+		if full != test.full || index != test.index {
+			t.Errorf("%d/%d ramp %d: want (%d, %d), got (%d, %d)\n",
+				test.stat.Current, test.stat.Total, test.ramp,
+				test.full, test.index, full, index)
+		}
+	}
+}
+
+func TestNsecMaxSpeed(t *testing.T) {
+	stat := &decor.Statistics{
+		Current:       10 * decor.GiB,
+		RollCurrent:   10 * decor.GiB,
+		RollStartTime: time.Now().Add(-time.Second),
+		MaxSpeed:      100 * decor.MiB,
+	}
+	want := "100MiB/s"
+	got := decor.Nsec("%s/s", decor.Unit_KiB, 0, 0)(stat, nil, nil)
+	if got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestSpeedAuto(t *testing.T) {
+	tests := []struct {
+		current int64
+		iec, si string
+	}{
+		{0, "0.0b  /s", "0.0b /s"},
+		{1500000, "1.4MiB/s", "1.5MB/s"},
+		{3 * decor.MiB, "3.0MiB/s", "3.1MB/s"},
+	}
+	for _, test := range tests {
+		stat := &decor.Statistics{
+			Current:       test.current,
+			RollCurrent:   test.current,
+			RollStartTime: time.Now().Add(-time.Second),
+		}
+		if got := decor.SpeedAuto("%s/s", 0, 0)(stat, nil, nil); got != test.iec {
+			t.Errorf("Want: %q, Got: %q\n", test.iec, got)
+		}
+		if got := decor.SpeedAutoSI("%s/s", 0, 0)(stat, nil, nil); got != test.si {
+			t.Errorf("Want: %q, Got: %q\n", test.si, got)
+		}
+	}
+
+	// between SI and IEC thresholds, 1000 and 1024
+	stat := &decor.Statistics{
+		Current:       1012,
+		RollCurrent:   1012,
+		RollStartTime: time.Now().Add(-time.Second),
+	}
+	if got := decor.SpeedAuto("%s/s", 0, 0)(stat, nil, nil); !strings.HasSuffix(got, "b  /s") {
+		t.Errorf("Want bytes per second, Got: %q\n", got)
+	}
+	if got := decor.SpeedAutoSI("%s/s", 0, 0)(stat, nil, nil); !strings.HasSuffix(got, "KB/s") {
+		t.Errorf("Want KB per second, Got: %q\n", got)
+	}
+}
+
+func TestETAClock(t *testing.T) {
+	fn := decor.ETAClock("15:04:05", 0, 0)
+	now := time.Now()
+
+	// 100 items in 100s, 100 items left
+	stat := &decor.Statistics{
+		Total:         200,
+		Current:       100,
+		RollCurrent:   100,
+		RollStartTime: now.Add(-100 * time.Second),
+	}
+	got := fn(stat, nil, nil)
+	var ok bool
+	for _, d := range []time.Duration{99, 100, 101} {
+		ok = ok || got == now.Add(d*time.Second).Format("15:04:05")
+	}
+	if !ok {
+		t.Errorf("Want: %q, Got: %q\n", now.Add(100*time.Second).Format("15:04:05"), got)
+	}
+
+	for _, stat := range []*decor.Statistics{
+		// no data yet
+		{Total: 200},
+		// too far away
+		{Total: 1e12, Current: 1, RollCurrent: 1, RollStartTime: now.Add(-time.Hour)},
+	} {
+		if got, want := fn(stat, nil, nil), "--:--"; got != want {
+			t.Errorf("Want: %q, Got: %q\n", want, got)
+		}
+	}
+}
+
+func TestShowAfter(t *testing.T) {
+	fn := decor.ShowAfter(decor.StaticName("ETA", 0, 0), 5*time.Second)
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{elapsed: time.Second, want: "   "},
+		{elapsed: 5 * time.Second, want: "ETA"},
+	}
+	for _, test := range tests {
+		got := fn(&decor.Statistics{TimeElapsed: test.elapsed}, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestShowIf(t *testing.T) {
+	fn := decor.ShowIf(decor.StaticName("done", 0, 0), func(s *decor.Statistics) bool {
+		return s.Completed
+	})
+	if got, want := fn(&decor.Statistics{}, nil, nil), "    "; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+	if got, want := fn(&decor.Statistics{Completed: true}, nil, nil), "done"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestShowIfColored(t *testing.T) {
+	fn := decor.ShowIf(decor.AutoColor(decor.StaticName("done", 0, 0)), func(s *decor.Statistics) bool {
+		return s.Completed
+	})
+	if got, want := fn(&decor.Statistics{}, nil, nil), "    "; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestAvgSize(t *testing.T) {
+	fn := decor.AvgSize("avg %s/file", decor.Unit_kB, 0, 0)
+	tests := []struct {
+		stat *decor.Statistics
+		want string
+	}{
+		{&decor.Statistics{Current: 1000}, ""},
+		{&decor.Statistics{Current: 6 * decor.MB, Current2: 5}, "avg 1.2MB/file"},
+	}
+	for _, test := range tests {
+		got := fn(test.stat, nil, nil)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	fn := decor.Conditional(func(s *decor.Statistics) bool {
+		return s.Completed
+	}, decor.StaticName("done", 0, 0), decor.Percentage(0, 0))
+
+	stat := &decor.Statistics{Total: 100, Current: 50}
+	if got, want := fn(stat, nil, nil), decor.PercentageString(stat); got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+	stat.Completed = true
+	if got, want := fn(stat, nil, nil), "done"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestAutoColor(t *testing.T) {
+	fn := decor.AutoColor(decor.StaticName("name", 0, 0))
+	tests := []struct {
+		id   int
+		want string
+	}{
+		{0, "\x1b[31mname\x1b[0m"},
+		{1, "\x1b[32mname\x1b[0m"},
+		{6, "\x1b[31mname\x1b[0m"},
+		{-1, "\x1b[36mname\x1b[0m"},
+	}
+	for _, test := range tests {
+		if got := fn(&decor.Statistics{ID: test.id}, nil, nil); got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestSpeedWindow(t *testing.T) {
+	now := time.Now()
+	stat := &decor.Statistics{
+		Current: 300,
+		Samples: []decor.Sample{
+			{Time: now.Add(-10 * time.Second), Current: 0},
+			{Time: now.Add(-2 * time.Second), Current: 100},
+			{Time: now.Add(-time.Second), Current: 200},
+		},
+	}
+	var requested time.Duration
+	stat.WantSamples = func(window time.Duration) { requested = window }
+	// 200 items within the last 2s
+	got := decor.SpeedWindow(3*time.Second, "%s/s", 0, 0, 0)(stat, nil, nil)
+	if got != "100.00/s" && got != "99.99/s" {
+		t.Errorf("Want: %q, Got: %q\n", "100.00/s", got)
+	}
+	if requested != 3*time.Second {
+		t.Errorf("Want samples requested for %v, Got: %v\n", 3*time.Second, requested)
+	}
+	// capped by MaxSpeed
+	stat.MaxSpeed = 50
+	got = decor.SpeedWindow(3*time.Second, "%s/s", 0, 0, 0)(stat, nil, nil)
+	if want := "50.00/s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+	// no samples within the window
+	stat.Samples = stat.Samples[:1]
+	got = decor.SpeedWindow(3*time.Second, "%s/s", 0, 0, 0)(stat, nil, nil)
+	if want := "0.00/s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	tests := []struct {
+		left time.Duration
+		want string
+	}{
+		{-time.Second, "00:00"},
+		{44*time.Second + time.Second/2, "00:45"},
+		{time.Hour + 2*time.Minute + 2*time.Second + time.Second/2, "1:02:03"},
+	}
+	for _, test := range tests {
+		fn := decor.Countdown(time.Now().Add(test.left), 0, 0)
+		if got := fn(&decor.Statistics{}, nil, nil); got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestPercentageOfTotal(t *testing.T) {
+	tests := []struct {
+		total int64
+		want  string
+	}{
+		{0, "    "},
+		{1000, "2.3% of total"},
+		{23, "100.0% of total"},
+	}
+	for _, test := range tests {
+		fn := decor.PercentageOfTotal(test.total, 4, 0)
+		if got := fn(&decor.Statistics{Total: 100, Current: 23}, nil, nil); got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	if got, want := decor.Static(" | ")(nil, nil, nil), " | "; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestProcessed(t *testing.T) {
+	stat := &decor.Statistics{Current: 3 * 1024 * 1024}
+	want := decor.CountersNoTotalString(stat, "%s", decor.Unit_KiB)
+	if got := decor.Processed(decor.Unit_KiB, 0, 0)(stat, nil, nil); got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	fn := decor.Remaining("%s/%s", 0, 0, 0)
+	tests := []struct {
+		current int64
+		want    string
+	}{
+		{0, "500/500"},
+		{120, "380/500"},
+		{500, "0/500"},
+		{600, "0/500"},
+	}
+	for _, test := range tests {
+		stat := &decor.Statistics{Total: 500, Current: test.current}
+		if got := fn(stat, nil, nil); got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestRemainingItems(t *testing.T) {
+	fn := decor.RemainingItems("file", "files", 4, 0)
+	tests := []struct {
+		total, current int64
+		want           string
+	}{
+		{0, 0, "    "},
+		{5, 2, "3 files remaining"},
+		{5, 4, "1 file remaining"},
+		{5, 5, "0 files remaining"},
+	}
+	for _, test := range tests {
+		stat := &decor.Statistics{Total: test.total, Current: test.current}
+		if got := fn(stat, nil, nil); got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestStatisticsPercentage(t *testing.T) {
+	tests := []struct {
+		stat *decor.Statistics
+		want float64
+	}{
+		{&decor.Statistics{Total: 0, Current: 10}, 0},
+		{&decor.Statistics{Total: 200, Current: 50}, 25},
+		{&decor.Statistics{Total: 200, Current: 200}, 100},
+		{&decor.Statistics{Total: 1 << 60, Current: 1<<60 - 1}, 99},
+	}
+	for _, test := range tests {
+		got := test.stat.Percentage()
+		if got < test.want || got >= test.want+1 || (test.want < 100 && got >= 100) {
+			t.Errorf("Want: %v, Got: %v\n", test.want, got)
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	stat := &decor.Statistics{Total: 100, Current: 42}
+	want := decor.Percentage(6, decor.DidentRight)(stat, nil, nil)
+	got := decor.NewBuilder().RightAlign().MinWidth(6).Build(decor.Percentage)(stat, nil, nil)
+	if got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
 type step struct {
 	stat *decor.Statistics
 	want string
@@ -108,6 +732,23 @@ func TestPercentageDSyncSpace(t *testing.T) {
 	testDecoratorConcurrently(t, dfn, testCases)
 }
 
+func TestDynamicNameAmbiguousWidth(t *testing.T) {
+	cond := runewidth.NewCondition()
+	cond.EastAsianWidth = true
+
+	testCases := [][]step{
+		[]step{
+			// "±" is ambiguous, 2 columns wide
+			{&decor.Statistics{ID: 0, WidthCond: cond}, "±±"},
+			{&decor.Statistics{ID: 1, WidthCond: cond}, " abc"},
+		},
+	}
+
+	names := []string{"±±", "abc"}
+	dfn := decor.DynamicName(func(s *decor.Statistics) string { return names[s.ID] }, 0, decor.DwidthSync)
+	testDecoratorConcurrently(t, dfn, testCases)
+}
+
 func testDecoratorConcurrently(t *testing.T, dfn decor.DecoratorFunc, testCases [][]step) {
 	if len(testCases) == 0 {
 		t.Fail()
@@ -1,8 +1,16 @@
 package mpb
 
 import (
+	"bytes"
+	"io/ioutil"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/james-antill/mpb/decor"
+	"github.com/mattn/go-runewidth"
 )
 
 func TestFillBar(t *testing.T) {
@@ -102,6 +110,216 @@ func TestFillBar(t *testing.T) {
 	}
 }
 
+func TestDrawFixedWidth(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.fixedWidth = true
+
+	got := draw(s, 10, prependWs, appendWs)
+	if len(got) != s.width {
+		t.Errorf("Want width: %d, Got: %d (%q)\n", s.width, len(got), got)
+	}
+}
+
+func TestDrawCountdown(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	tests := []struct {
+		current int64
+		want    []byte
+	}{
+		{current: 0, want: []byte("[========]")},
+		{current: 25, want: []byte("[=====>--]")},
+		{current: 100, want: []byte("[--------]")},
+	}
+	for _, test := range tests {
+		s := newTestState()
+		s.fmtFill, s.fmtFillBytes = nil, nil
+		s.width = 10
+		s.total = 100
+		s.current = test.current
+		s.countdown = true
+		got := draw(s, 10, prependWs, appendWs)
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestDrawCompletedFill(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.width = 10
+	s.total = 100
+	s.current = 100
+	WithCompletedFill("#")(s)
+
+	got := draw(s, 10, prependWs, appendWs)
+	if want := []byte("##########"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestTruncateVisible(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"abcdef", 3, "abc"},
+		{"\x1b[31mabcdef\x1b[0m", 3, "\x1b[31mabc\x1b[0m"},
+		{"ab\x1b[31mcdef\x1b[0m", 2, "ab\x1b[31m\x1b[0m"},
+		{"\x1b[1;31mab\x1b[0m", 0, "\x1b[1;31m\x1b[0m"},
+	}
+	for _, test := range tests {
+		got := truncateVisible(runewidth.DefaultCondition, []byte(test.in), test.width)
+		if string(got) != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestDrawMinBarWidth(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 1)
+	appendWs := newWidthSync(nil, 1, 1)
+
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.prependFuncs = []decor.DecoratorFunc{decor.StaticName("prepend", 0, 0)}
+	s.appendFuncs = []decor.DecoratorFunc{decor.StaticName("append", 0, 0)}
+	WithMinBarWidth(10)(s)
+
+	got := draw(s, 20, prependWs, appendWs)
+	if want := []byte("prepend[====----]app"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestDrawRightAlignedAppend(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 1)
+
+	s := newTestState()
+	s.width = 10
+	s.total = 100
+	s.current = 50
+	s.fixedWidth = true
+	s.appendFuncs = []decor.DecoratorFunc{decor.StaticName("eta", 0, 0)}
+	WithRightAlignedAppend()(s)
+
+	got := draw(s, 20, prependWs, appendWs)
+	if want := []byte("[====----]       eta"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestDrawMinBarWidthRightAligned(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 1)
+
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.appendFuncs = []decor.DecoratorFunc{decor.StaticName("ab世界", 0, 0)}
+	// measure columns, see WithAmbiguousWidth
+	s.widthCond = runewidth.NewCondition()
+	WithMinBarWidth(10)(s)
+	WithRightAlignedAppend()(s)
+
+	// "界" doesn't fit into the last column, which is padded instead
+	got := draw(s, 15, prependWs, appendWs)
+	if want := []byte("[====----] ab世"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestDrawCompletionAppend(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 1)
+
+	s := newTestState()
+	s.width = 10
+	s.total = 100
+	s.current = 50
+	s.fixedWidth = true
+	WithCompletionAppend(decor.StaticName("12MiB", 0, 0))(s)
+
+	if got, want := draw(s, 20, prependWs, appendWs), []byte("[====----]     "); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+	s.current = 100
+	s.completed = true
+	if got, want := draw(s, 20, prependWs, appendWs), []byte("----------12MiB"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestDrawAmbiguousWidth(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 1)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.prependFuncs = []decor.DecoratorFunc{decor.StaticName("██", 0, 0)}
+	cond := runewidth.NewCondition()
+	cond.EastAsianWidth = true
+	barWidthCond(cond)(s)
+
+	got := draw(s, 14, prependWs, appendWs)
+	if want := []byte("██[====----]"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestDrawColoredPrepend(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 1)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.prependFuncs = []decor.DecoratorFunc{decor.AutoColor(decor.StaticName("ab", 0, 0))}
+
+	got := draw(s, 12, prependWs, appendWs)
+	if want := []byte("\x1b[31mab\x1b[0m[====----]"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestDrawSegments(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.width = 12
+	s.total = 100
+	s.current = 30
+	s.segments = []Segment{
+		{Start: 0, End: 50, Current: 20},
+		{Start: 50, End: 100, Current: 10},
+	}
+
+	want := []byte("[==---=----]")
+	got := draw(s, 12, prependWs, appendWs)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
 func newTestState() *state {
 	s := &state{
 		trimLeftSpace:  true,
@@ -110,3 +328,336 @@ func newTestState() *state {
 	s.updateFormat("[=>-]", []string{"="})
 	return s
 }
+
+func TestDrawSpinner(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.simpleSpinner = true
+	for i, want := range []string{"[-]", `[\]`, "[|]", "[/]", "[-]"} {
+		s.spinnerIndex = i
+		got := draw(s, 10, prependWs, appendWs)
+		if string(got) != want {
+			t.Errorf("Want: %q, Got: %q\n", want, got)
+		}
+	}
+}
+
+func TestDrawSpinnerDone(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	s := newTestState()
+	s.simpleSpinner = true
+	s.spinnerDone = "✓"
+	s.spinnerIndex = 2
+	s.completed = true
+	want := "[✓]"
+	if got := draw(s, 10, prependWs, appendWs); string(got) != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+	WithSpinnerAborted("✗")(s)
+	s.aborted = true
+	want = "[✗]"
+	if got := draw(s, 10, prependWs, appendWs); string(got) != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestUpdateTailETA(t *testing.T) {
+	s := newTestState()
+	s.total = 100
+	s.current = 96
+	s.startTime = time.Now().Add(-10 * time.Second)
+	s.initETA()
+
+	s.updateTailETA()
+	if s.tailETATime.IsZero() {
+		t.Fatal("Expected tail ETA to be set")
+	}
+	first := s.tailETA
+
+	// slow down, so plain estimate grows
+	s.startTime = s.startTime.Add(-time.Minute)
+	s.initETA()
+	s.updateTailETA()
+	if s.tailETA > first {
+		t.Errorf("Expected ETA not to increase: %v > %v\n", s.tailETA, first)
+	}
+
+	s.current = 50
+	s.updateTailETA()
+	if !s.tailETATime.IsZero() {
+		t.Error("Expected tail ETA to be unset below threshold")
+	}
+}
+
+func TestUpdateETAEwma(t *testing.T) {
+	for _, alpha := range []float64{0.25, 1} {
+		s := newTestState()
+		s.etaAlpha = alpha
+		s.startTime = time.Now().Add(-time.Second)
+		s.initETA()
+		s.updateETA(100)
+		// first sample is taken as is
+		if s.ewmaRate < 90 || s.ewmaRate > 100 {
+			t.Fatalf("alpha %v: expected rate ~100, got %v\n", alpha, s.ewmaRate)
+		}
+		prev := s.ewmaRate
+		s.ewmaTime = time.Now().Add(-time.Second)
+		s.updateETA(200)
+		want := alpha*200 + (1-alpha)*prev
+		if s.ewmaRate < want-10 || s.ewmaRate > want {
+			t.Errorf("alpha %v: expected rate ~%v, got %v\n", alpha, want, s.ewmaRate)
+		}
+	}
+}
+
+func TestDrawMarquee(t *testing.T) {
+	prependWs := newWidthSync(nil, 1, 0)
+	appendWs := newWidthSync(nil, 1, 0)
+
+	tests := []struct {
+		index     int
+		completed bool
+		want      string
+	}{
+		{index: 0, want: "[==--------]"},
+		{index: 3, want: "[---==-----]"},
+		{index: 8, want: "[--------==]"},
+		{index: 11, want: "[-----==---]"},
+		{index: 16, want: "[==--------]"},
+		{index: 5, completed: true, want: "[==========]"},
+	}
+	for _, test := range tests {
+		s := newTestState()
+		s.width = 12
+		s.simpleSpinner = true
+		s.marquee = true
+		s.spinnerIndex = test.index
+		s.completed = test.completed
+		got := draw(s, 12, prependWs, appendWs)
+		if string(got) != test.want {
+			t.Errorf("Index %d: want %q, got %q\n", test.index, test.want, got)
+		}
+	}
+}
+
+func BenchmarkDraw(b *testing.B) {
+	prependWs := newWidthSync(nil, 1, 2)
+	appendWs := newWidthSync(nil, 1, 1)
+
+	s := newTestState()
+	s.width = 80
+	s.total = 1000
+	s.current = 420
+	s.prependFuncs = []decor.DecoratorFunc{
+		decor.StaticName("Bar#1:", 0, 0),
+		decor.Counters("%s / %s", decor.Unit_KiB, 0, 0),
+	}
+	s.appendFuncs = []decor.DecoratorFunc{decor.Percentage(5, 0)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		draw(s, 100, prependWs, appendWs)
+	}
+}
+
+func TestFanInSkip(t *testing.T) {
+	inputs := make([]<-chan []byte, 5)
+	for i := range inputs {
+		ch := make(chan []byte, 1)
+		ch <- []byte{byte('0' + i)}
+		inputs[i] = ch
+	}
+	var got []string
+	for buf := range fanIn(3, inputs...) {
+		got = append(got, string(buf))
+	}
+	if want := []string{"3", "4"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestPausedStatistics(t *testing.T) {
+	s := newTestState()
+	now := time.Now()
+	s.startTime = now.Add(-3 * time.Second)
+	s.initETA()
+	s.pausedAt = now.Add(-2 * time.Second)
+
+	within := func(d, want time.Duration) bool {
+		return d >= want && d < want+time.Second/2
+	}
+	if stat := newStatistics(s); !within(stat.TimeElapsed, time.Second) {
+		t.Errorf("Want paused elapsed about 1s, Got: %v\n", stat.TimeElapsed)
+	}
+
+	// resume
+	s.shiftTimes(time.Now())
+	s.pausedAt = time.Time{}
+	if stat := newStatistics(s); !within(stat.TimeElapsed, time.Second) {
+		t.Errorf("Want resumed elapsed about 1s, Got: %v\n", stat.TimeElapsed)
+	}
+	if !s.rollTime[0].Equal(s.startTime) {
+		t.Errorf("Want roll time shifted with start time, Got: %v\n", s.rollTime[0])
+	}
+}
+
+func TestPausedUpdateETA(t *testing.T) {
+	s := newTestState()
+	now := time.Now()
+	s.startTime = now.Add(-10 * time.Second)
+	s.initETA()
+	s.pausedAt = now.Add(-5 * time.Second)
+	s.sampleWindow = new(int64)
+	*s.sampleWindow = int64(time.Minute)
+
+	// incremented while paused
+	s.updateETA(10)
+	s.current += 10
+
+	// resume
+	s.shiftTimes(time.Now())
+	s.pausedAt = time.Time{}
+	now = time.Now()
+	for i, rt := range s.rollTime {
+		if rt.After(now) {
+			t.Errorf("Want roll time %d not in the future, Got: %v\n", i, rt.Sub(now))
+		}
+	}
+	for _, sample := range s.samples {
+		if sample.Time.After(now) {
+			t.Errorf("Want sample not in the future, Got: %v\n", sample.Time.Sub(now))
+		}
+	}
+	if s.ewmaTime.After(now) {
+		t.Errorf("Want ewma time not in the future, Got: %v\n", s.ewmaTime.Sub(now))
+	}
+	if stat := newStatistics(s); stat.TimeElapsed < 5*time.Second || stat.TimeElapsed > 6*time.Second {
+		t.Errorf("Want elapsed about 5s, Got: %v\n", stat.TimeElapsed)
+	}
+}
+
+func TestSnapshotDone(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b := newBar(100, &wg, nil)
+	b.statistics().WantSamples(time.Minute)
+	b.Incr(10)
+	b.Abort()
+	wg.Wait()
+
+	st := b.snapshot()
+	if len(st.samples) == 0 {
+		t.Fatal("Want samples in snapshot")
+	}
+	st.samples[0].Current = -1
+	if b.cacheState.samples[0].Current == -1 {
+		t.Error("Want samples of done bar's snapshot to be a copy")
+	}
+}
+
+func TestResetStopsTicker(t *testing.T) {
+	p := New(Output(ioutil.Discard), WithRefreshRate(time.Millisecond))
+	for i := 0; i < 10; i++ {
+		p.AddBar(1).Incr(1)
+		p.Reset()
+	}
+	p.Stop()
+	<-p.done
+
+	ticker := p.cacheConf.ticker
+	// drop a tick, which could have been sent before ticker was stopped
+	select {
+	case <-ticker.C:
+	default:
+	}
+	select {
+	case <-ticker.C:
+		t.Error("Want ticker to be stopped, once p has quit")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestAddSample(t *testing.T) {
+	s := newTestState()
+	s.addSample(1)
+	if len(s.samples) != 0 {
+		t.Errorf("Want no samples, until requested, Got: %v\n", s.samples)
+	}
+	s.sampleWindow = new(int64)
+	*s.sampleWindow = int64(time.Hour)
+	s.addSample(1)
+	s.addSample(2)
+	if len(s.samples) != 1 || s.samples[0].Current != 2 {
+		t.Errorf("Want close samples merged, Got: %v\n", s.samples)
+	}
+	for i := 0; i < speedSamples+10; i++ {
+		// pretend the last sample is old enough
+		s.samples[len(s.samples)-1].Time = time.Now().Add(-speedSampleEvery)
+		s.addSample(int64(i))
+	}
+	if len(s.samples) != speedSamples {
+		t.Errorf("Want samples capped at %d, Got: %d\n", speedSamples, len(s.samples))
+	}
+	if last := s.samples[len(s.samples)-1].Current; last != speedSamples+9 {
+		t.Errorf("Want the newest sample kept, Got: %d\n", last)
+	}
+
+	// samples older than window are dropped
+	*s.sampleWindow = int64(time.Second)
+	for i := range s.samples {
+		s.samples[i].Time = time.Now().Add(-2 * time.Second)
+	}
+	s.addSample(1)
+	if len(s.samples) != 1 {
+		t.Errorf("Want samples out of window dropped, Got: %d\n", len(s.samples))
+	}
+}
+
+func TestPinFrame(t *testing.T) {
+	got := pinFrame([]byte("a\nb\nc\n"), 2)
+	want := []byte("\x1b7\x1b[Ha\x1b[K\r\nb\x1b[K\x1b8")
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestReaderLimitKeepOnError(t *testing.T) {
+	p := New(Output(ioutil.Discard))
+	bar := p.AddBar(10)
+	var sum int
+	r := &Reader{
+		Reader:  strings.NewReader("0123456789abcdef"),
+		bars:    []*Bar{bar},
+		cb:      func(n int) { sum += n },
+		limit:   bar.Total(),
+		noAbort: true,
+	}
+	n, err := r.Read(make([]byte, 16))
+	if n != 10 || err != ErrReadLimit {
+		t.Errorf("Want: 10, %v, Got: %d, %v\n", ErrReadLimit, n, err)
+	}
+	if sum != 10 {
+		t.Errorf("Want callback sum: 10, Got: %d\n", sum)
+	}
+	if stats, _ := p.StopStats(); stats.Aborted {
+		t.Error("Want bar not aborted, with KeepOnError")
+	}
+	if current := bar.Current(); current != 10 {
+		t.Errorf("Want current: 10, Got: %d\n", current)
+	}
+}
+
+func TestInlineWriterEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	w := &inlineWriter{out: &buf}
+	w.Write([]byte("\x1b[31mabc\x1b[0m\n"))
+	w.Write([]byte("ab\n"))
+	want := "\r\x1b[31mabc\x1b[0m\rab "
+	if got := buf.String(); got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
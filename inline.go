@@ -0,0 +1,45 @@
+package mpb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/james-antill/mpb/cwriter"
+	"github.com/mattn/go-runewidth"
+)
+
+// NewInline creates Progress with single bar, rendered on a single line of w,
+// which is overwritten in place with "\r" on every refresh, like
+// "Downloading [===>  ] 45% 1.2MB/s eta 3s". No trailing new line is written,
+// so it's up to the caller to write one, once p.Stop has returned.
+func NewInline(total int64, w io.Writer, options ...BarOption) (*Progress, *Bar) {
+	p := New(Output(&inlineWriter{out: w}), WithDumbTerminal())
+	return p, p.AddBar(total, options...)
+}
+
+// inlineWriter writes every frame as "\r" followed by its first line,
+// padded with spaces to the width of the previous one.
+type inlineWriter struct {
+	out       io.Writer
+	prevWidth int
+}
+
+func (w *inlineWriter) Write(p []byte) (int, error) {
+	line := p
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	// escapes, like colors, take no columns
+	width := runewidth.StringWidth(string(cwriter.StripEscapes(line)))
+	buf := make([]byte, 0, len(line)+2)
+	buf = append(buf, '\r')
+	buf = append(buf, line...)
+	if pad := w.prevWidth - width; pad > 0 {
+		buf = append(buf, bytes.Repeat([]byte{' '}, pad)...)
+	}
+	w.prevWidth = width
+	if _, err := w.out.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,53 @@
+package mpb
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsUTF8Locale(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   bool
+	}{
+		{"", false},
+		{"C", false},
+		{"POSIX", false},
+		{"C.UTF-8", true},
+		{"C.utf8", true},
+		{"en_US.UTF-8", true},
+		{"en_US.utf-8", true},
+		{"de_DE.UTF-8@euro", true},
+		{"en_US.ISO-8859-1", false},
+		{"ja_JP.eucJP", false},
+	}
+	for _, test := range tests {
+		if got := isUTF8Locale(test.locale); got != test.want {
+			t.Errorf("%q: want %v, got %v\n", test.locale, test.want, got)
+		}
+	}
+}
+
+func TestCurrentLocale(t *testing.T) {
+	vars := [...]string{"LC_ALL", "LC_CTYPE", "LANG"}
+	saved := make(map[string]string)
+	for _, name := range vars {
+		saved[name] = os.Getenv(name)
+	}
+	defer func() {
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}()
+
+	os.Setenv("LC_ALL", "")
+	os.Setenv("LC_CTYPE", "C.UTF-8")
+	os.Setenv("LANG", "C")
+	if got := currentLocale(); got != "C.UTF-8" {
+		t.Errorf("Want: %q, got: %q\n", "C.UTF-8", got)
+	}
+	os.Setenv("LC_ALL", "POSIX")
+	if got := currentLocale(); got != "POSIX" {
+		t.Errorf("Want: %q, got: %q\n", "POSIX", got)
+	}
+}
@@ -0,0 +1,43 @@
+package mpb
+
+import "io"
+
+// NewLogWriter returns writer, which could be set by log.SetOutput, so log
+// lines are written above the bars of p on its next refresh, rather than
+// being clobbered by them. While p has no bars, lines are written at once.
+// Once p has been stopped, lines are written below the last frame.
+func NewLogWriter(p *Progress) io.Writer {
+	return &logWriter{p: p}
+}
+
+type logWriter struct {
+	p *Progress
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	// caller may reuse b, once Write returns
+	buf := append([]byte(nil), b...)
+	select {
+	case w.p.ops <- func(c *pConf) {
+		c.cw.Persist(buf)
+		if len(c.bars) == 0 {
+			// there's no frame to be rendered, which would flush buf
+			c.cw.Flush()
+		}
+		for _, w := range c.extraOutputs {
+			w.Write(buf)
+		}
+	}:
+	case <-w.p.quit:
+		<-w.p.done
+		c := &w.p.cacheConf
+		c.cw.Persist(buf)
+		if err := c.cw.Flush(); err != nil {
+			return 0, err
+		}
+		for _, w := range c.extraOutputs {
+			w.Write(buf)
+		}
+	}
+	return len(b), nil
+}
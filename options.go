@@ -3,11 +3,13 @@ package mpb
 import (
 	"io"
 	"io/ioutil"
+	"log"
 	"sync"
 	"time"
 	"unicode/utf8"
 
 	"github.com/james-antill/mpb/cwriter"
+	"github.com/mattn/go-runewidth"
 )
 
 // ProgressOption is a function option which changes the default behavior of
@@ -59,6 +61,147 @@ func WithBeforeRenderFunc(f BeforeRender) ProgressOption {
 	}
 }
 
+// WithFinalRender makes p.Stop() render all bars once more, after they have
+// been completed. So the terminal is left with bars in their final state,
+// rather than with the last frame rendered by the ticker.
+func WithFinalRender() ProgressOption {
+	return func(c *pConf) {
+		c.finalRender = true
+	}
+}
+
+// WithDebugLog provided logger will be used to log render timings on each
+// refresh: number of bars, time spent until width sync of decorators is done,
+// and total render time. Could be useful to tune refresh rate. If renders
+// consistently take longer than refresh rate, a warning is logged too.
+func WithDebugLog(logger *log.Logger) ProgressOption {
+	return func(c *pConf) {
+		c.debugLog = logger
+	}
+}
+
+// WithRenderConcurrency makes bars to be rendered by n workers, instead of a
+// goroutine per bar on each refresh. Could be useful with thousands of bars.
+// As workers can't wait for each other, decorators with DwidthSync bit set
+// get max width of their column from the previous refresh.
+func WithRenderConcurrency(n int) ProgressOption {
+	return func(c *pConf) {
+		if n > 0 {
+			c.renderConcurrency = n
+		}
+	}
+}
+
+// WithColumns arranges up to n bars per terminal row, each one gets 1/n of
+// terminal width. Makes sense with many short bars.
+func WithColumns(n int) ProgressOption {
+	return func(c *pConf) {
+		if n > 1 {
+			c.columns = n
+		}
+	}
+}
+
+// WithUTF8Fill forces UTF-8 block elements fill (if true) or ASCII fill,
+// instead of detecting it from locale environment variables.
+func WithUTF8Fill(enable bool) ProgressOption {
+	return func(c *pConf) {
+		if enable {
+			c.fmtFill = pmultiFillUTF8[:]
+		} else {
+			c.fmtFill = pmultiFillASCII[:]
+		}
+	}
+}
+
+// WithDumbTerminal renders frames append only, without cursor control
+// sequences. It's the default, if TERM environment variable is "dumb".
+// Consider slower WithRefreshRate, as every frame is kept in the output.
+func WithDumbTerminal() ProgressOption {
+	return func(c *pConf) {
+		c.dumbTerminal = true
+	}
+}
+
+// WithHeader renders result of fn as a line above the bars, on every refresh.
+// Something like "Downloading 16 files...".
+func WithHeader(fn func() string) ProgressOption {
+	return func(c *pConf) {
+		c.header = fn
+	}
+}
+
+// WithSummaryTable makes Stop to write a table to w, after all bars have
+// completed. Table has a line per bar, with its name (see BarName) or ID,
+// final amount, elapsed time and average speed. Elapsed time is measured till
+// the bar has completed, not till Stop; it's "-" for bars, which made no progress.
+func WithSummaryTable(w io.Writer) ProgressOption {
+	return func(c *pConf) {
+		c.summary = w
+	}
+}
+
+// WithNoTruncate renders all bars on every refresh, even if they don't fit
+// terminal's height. By default only the last bars, which fit, are rendered.
+// Lines scrolled off the screen can't be redrawn, so only the visible ones are
+// updated on refresh, while the rest keep their state from when they have
+// scrolled off.
+func WithNoTruncate() ProgressOption {
+	return func(c *pConf) {
+		c.noTruncate = true
+	}
+}
+
+// WithCompletionNotify sets fn to be called once, when all bars have
+// completed, or p.Stop has been called. If fn is nil, terminal bell "\a" is
+// written to the output.
+func WithCompletionNotify(fn func()) ProgressOption {
+	return func(c *pConf) {
+		c.completionNotify = fn
+		if fn == nil {
+			c.completionNotify = func() {
+				if !c.quiet {
+					c.cw.Bell()
+				}
+			}
+		}
+	}
+}
+
+// WithAmbiguousWidth sets width of East Asian ambiguous runes, like block
+// elements of the bar's fill, either 1 or 2 columns, for terminals which
+// disagree with the locale. It's applied to bars' layout and to width sync of
+// decorators (see decor.Statistics.StringWidth). Other widths are ignored.
+func WithAmbiguousWidth(width int) ProgressOption {
+	return func(c *pConf) {
+		if width != 1 && width != 2 {
+			return
+		}
+		c.widthCond = runewidth.NewCondition()
+		c.widthCond.EastAsianWidth = width == 2
+	}
+}
+
+// WithQuiet makes p to produce no output, including terminal bell of
+// WithCompletionNotify and scroll region of WithPinnedRegion, while bars still
+// track their state, so StopStats, summary table (see WithSummaryTable) and
+// bars' statistics remain accurate. Useful for a quiet mode of a command.
+func WithQuiet() ProgressOption {
+	return func(c *pConf) {
+		c.quiet = true
+	}
+}
+
+// WithPinnedRegion makes bars to be rendered to the top lines of the
+// terminal, while the rest of it is set as scroll region (DECSTBM), so output
+// of the program, like fmt.Println, scrolls below the bars. Bars, which
+// don't fit, aren't rendered. Full scroll region is restored by p.Stop.
+func WithPinnedRegion(lines int) ProgressOption {
+	return func(c *pConf) {
+		c.pinned = lines
+	}
+}
+
 // WithCancel provide your cancel channel,
 // which you plan to close at some point.
 func WithCancel(ch <-chan struct{}) ProgressOption {
@@ -84,6 +227,27 @@ func Output(w io.Writer) ProgressOption {
 	}
 }
 
+// WithOutputs is like Output, but additionally renders to each of extra
+// writers. Primary writer gets the usual cursor controlled output, while extra
+// writers get every rendered frame as plain lines, without any cursor control
+// sequences. Could be useful to keep a copy of the progress in a log file.
+func WithOutputs(primary io.Writer, extra ...io.Writer) ProgressOption {
+	return func(c *pConf) {
+		Output(primary)(c)
+		c.extraOutputs = extra
+	}
+}
+
+// WithPreInterceptor is like OutputInterceptors, but fn is called before the
+// bars are written, so it's a way to output something above the bars (below
+// WithHeader line, if any), while they're rendering. Every line written by
+// fn must end with new line.
+func WithPreInterceptor(fn func(io.Writer)) ProgressOption {
+	return func(c *pConf) {
+		c.preInterceptors = append(c.preInterceptors, fn)
+	}
+}
+
 // OutputInterceptors provides a way to write to the underlying progress pool's
 // writer. Could be useful if you want to output something below the bars, while
 // they're rendering.
@@ -1,21 +1,34 @@
 package mpb
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"log"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/james-antill/mpb/cwriter"
 	"github.com/james-antill/mpb/decor"
+	"github.com/mattn/go-runewidth"
 )
 
 type (
 	// BeforeRender is a func, which gets called before render process
 	BeforeRender func([]*Bar)
 
+	// BarSpec describes a bar to be created by Progress.AddBars
+	BarSpec struct {
+		Total   int64
+		Options []BarOption
+	}
+
 	widthSync struct {
 		Listen []chan int
 		Result []chan int
@@ -31,9 +44,47 @@ type (
 		rr           time.Duration
 		ewg          *sync.WaitGroup
 		cw           *cwriter.Writer
+		extraOutputs []io.Writer
 		ticker       *time.Ticker
 		beforeRender BeforeRender
 		interceptors []func(io.Writer)
+		finalRender  bool
+		debugLog     *log.Logger
+		// number of consecutive renders, which took longer than rr
+		slowRenders int
+		// render append only, without cursor control sequences
+		dumbTerminal bool
+		// if not nil, its result is rendered above the bars
+		header func() string
+		// like interceptors, but called before the bars are written
+		preInterceptors []func(io.Writer)
+		// if not nil, summary table is written to it, after bars have quit
+		summary io.Writer
+		// lines of the last rendered frame, one per visible bar
+		frame []string
+		// if true, all bars are rendered, even if they don't fit terminal
+		noTruncate bool
+		// rendered as a line below the bars, see Progress.SetFooter
+		footer []decor.DecoratorFunc
+		// called once all bars have completed, see WithCompletionNotify
+		completionNotify   func()
+		completionNotified bool
+		// if not nil, used to measure width, see WithAmbiguousWidth
+		widthCond *runewidth.Condition
+		// if true, frames are rendered, but not written, see WithQuiet
+		quiet bool
+		// if > 0, frames are rendered to that many top lines, see
+		// WithPinnedRegion
+		pinned int
+
+		// if > 0, bars are rendered by that many workers, with widths
+		// synced on the previous render
+		renderConcurrency int
+		prependWidths     []int
+		appendWidths      []int
+
+		// if > 1, up to that many bars are rendered per terminal row
+		columns int
 
 		shutdownNotifier chan struct{}
 		cancel           <-chan struct{}
@@ -49,6 +100,8 @@ const (
 	pformat = "[=> ]"
 	// Do we want to try to use the utf8 progressbar
 	utf8Fill = true
+	// number of consecutive renders longer than refresh rate, before warning
+	slowRendersWarn = 5
 )
 
 var (
@@ -65,6 +118,9 @@ var (
 		"\xe2\x96\x88"}
 )
 
+// ErrStopped is returned, when Progress has been stopped already
+var ErrStopped = errors.New("mpb: progress has been stopped")
+
 // Progress represents the container that renders Progress bars
 type Progress struct {
 	// wg for internal rendering sync
@@ -77,6 +133,9 @@ type Progress struct {
 	// done channel is receiveable after p.server has been quit
 	done chan struct{}
 	ops  chan func(*pConf)
+
+	// conf, as p.server has left it on quit
+	cacheConf pConf
 }
 
 // Default sort the completed bars away, up the screen,
@@ -103,7 +162,7 @@ func defaultSort(bs []*Bar) {
 func New(options ...ProgressOption) *Progress {
 	// This is ugly, but it's what python does for stdout.encoding.
 	var fill = pmultiFillASCII[:]
-	if utf8Fill && strings.HasSuffix(strings.ToLower(os.Getenv("LANG")), ".utf-8") {
+	if utf8Fill && isUTF8Locale(currentLocale()) {
 		fill = pmultiFillUTF8[:]
 	}
 
@@ -117,12 +176,26 @@ func New(options ...ProgressOption) *Progress {
 		cw:           cwriter.New(os.Stderr),
 		rr:           prr,
 		ticker:       time.NewTicker(prr),
+		dumbTerminal: os.Getenv("TERM") == "dumb",
 	}
 
 	for _, opt := range options {
 		opt(&conf)
 	}
 
+	if conf.dumbTerminal {
+		conf.cw.SetPlain(true)
+	}
+	if conf.pinned > 0 && !conf.quiet {
+		// lines are overwritten in place, see pinFrame
+		conf.cw.SetPlain(true)
+		_, th, err := cwriter.GetTermSize()
+		if err != nil || th <= conf.pinned {
+			th = 24
+		}
+		conf.cw.SetScrollRegion(conf.pinned+1, th)
+	}
+
 	p := &Progress{
 		ewg:  conf.ewg,
 		wg:   new(sync.WaitGroup),
@@ -134,33 +207,160 @@ func New(options ...ProgressOption) *Progress {
 	return p
 }
 
+// currentLocale returns locale of character classification, first non empty
+// one of LC_ALL, LC_CTYPE and LANG, as libc does.
+func currentLocale() string {
+	for _, name := range [...]string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// isUTF8Locale reports whether locale's codeset is UTF-8, like "C.UTF-8" or
+// "en_US.utf8@euro".
+func isUTF8Locale(locale string) bool {
+	i := strings.IndexByte(locale, '.')
+	if i < 0 {
+		return false
+	}
+	codeset := strings.ToLower(locale[i+1:])
+	if j := strings.IndexByte(codeset, '@'); j >= 0 {
+		codeset = codeset[:j]
+	}
+	return codeset == "utf-8" || codeset == "utf8"
+}
+
 // AddBar creates a new progress bar and adds to the container.
+// If p has been stopped already, a dummy bar is returned, which is never
+// rendered and all its methods are no-ops. Use AddBarErr, if you need to know
+// about that.
 func (p *Progress) AddBar(total int64, options ...BarOption) *Bar {
+	b, err := p.AddBarErr(total, options...)
+	if err != nil {
+		return newDeadBar()
+	}
+	return b
+}
+
+// AddBarErr is like AddBar, but returns ErrStopped if p has been stopped
+// already.
+func (p *Progress) AddBarErr(total int64, options ...BarOption) (*Bar, error) {
 	result := make(chan *Bar, 1)
 	op := func(c *pConf) {
 		options = append(options, barWidth(c.width))
 		options = append(options, barFormat(c.format, c.fmtFill))
+		options = append(options, barRefreshRate(c.rr))
+		if c.widthCond != nil {
+			options = append(options, barWidthCond(c.widthCond))
+		}
 		b := newBar(total, p.wg, c.cancel, options...)
 		c.bars = append(c.bars, b)
 		p.wg.Add(1)
 		result <- b
 	}
 	select {
+	case p.ops <- op:
+		return <-result, nil
+	case <-p.quit:
+		return nil, ErrStopped
+	}
+}
+
+// AddBars creates a new progress bar for every spec and adds them to the
+// container, all at once. If p has been stopped already, dummy bars are
+// returned, like with AddBar.
+func (p *Progress) AddBars(specs []BarSpec) []*Bar {
+	result := make(chan []*Bar, 1)
+	op := func(c *pConf) {
+		bars := make([]*Bar, len(specs))
+		p.wg.Add(len(specs))
+		for i, spec := range specs {
+			options := append(spec.Options[:len(spec.Options):len(spec.Options)],
+				barWidth(c.width), barFormat(c.format, c.fmtFill), barRefreshRate(c.rr))
+			if c.widthCond != nil {
+				options = append(options, barWidthCond(c.widthCond))
+			}
+			bars[i] = newBar(spec.Total, p.wg, c.cancel, options...)
+		}
+		c.bars = append(c.bars, bars...)
+		result <- bars
+	}
+	select {
 	case p.ops <- op:
 		return <-result
 	case <-p.quit:
-		return new(Bar)
+		bars := make([]*Bar, len(specs))
+		for i := range bars {
+			bars[i] = newDeadBar()
+		}
+		return bars
 	}
 }
 
 // AddBarDef creates a new progress bar with sane default options.
+// If total is unknown (<= 0), for example when copying from a pipe, the bar
+// shows amount of data copied so far and speed, next to the spinner.
 func (p *Progress) AddBarDef(total int64, name string, unit decor.Units,
+	options ...BarOption) *Bar {
+	return p.addBarDef(total, decor.StaticName(name, 0, 0), unit, options...)
+}
+
+// AddBarDefDynamic is like AddBarDef, but bar's name is provided by nameFn
+// on every render, like a file currently being processed.
+func (p *Progress) AddBarDefDynamic(total int64, nameFn func(*decor.Statistics) string,
+	unit decor.Units, options ...BarOption) *Bar {
+	return p.addBarDef(total, decor.DynamicName(nameFn, 0, 0), unit, options...)
+}
+
+// AddBarDefCountdown is like AddBarDef, but creates countdown bar (see
+// WithCountdown), which shows remaining amount and percentage, like for
+// "deleting 500/500 files" down to "0/500".
+func (p *Progress) AddBarDefCountdown(total int64, name string, unit decor.Units,
+	options ...BarOption) *Bar {
+	opts := []BarOption{
+		WithCountdown(),
+		PrependDecorators(
+			decor.StaticName(name, 0, 0),
+			decor.Remaining("%s/%s ", unit, 0, 0),
+			decor.PercentageRemaining(3, 0)),
+		AppendDecorators(decor.ETA(4, decor.DwidthSync)),
+	}
+	opts = append(opts, options...)
+	return p.AddBar(total, opts...)
+}
+
+// AddTimerBar creates bar, driven by wall clock time rather than Incr calls,
+// like for timeouts and cooldowns. Its current is time elapsed since its
+// creation, updated on every refresh, and it completes after d. Amounts are
+// in nanoseconds, as time.Duration.
+func (p *Progress) AddTimerBar(d time.Duration, name string, options ...BarOption) *Bar {
+	opts := []BarOption{
+		withTimer(time.Now()),
+		PrependDecorators(
+			decor.StaticName(name, 0, 0),
+			decor.Percentage(3, 0)),
+		AppendDecorators(decor.ETA(4, decor.DwidthSync)),
+	}
+	opts = append(opts, options...)
+	return p.AddBar(int64(d), opts...)
+}
+
+func (p *Progress) addBarDef(total int64, name decor.DecoratorFunc, unit decor.Units,
 	options ...BarOption) *Bar {
 	var opts []BarOption
-	opts = append(opts, PrependDecorators(
-		decor.StaticName(name, 0, 0),
-		decor.DefDataPreBar(unit)))
-	opts = append(opts, AppendDecorators(decor.ETA(4, decor.DwidthSync)))
+	if total <= 0 {
+		opts = append(opts, PrependDecorators(
+			name,
+			decor.DefDataNoTotal(unit)))
+		opts = append(opts, AppendDecorators(decor.Elapsed(4, decor.DwidthSync)))
+	} else {
+		opts = append(opts, PrependDecorators(
+			name,
+			decor.DefDataPreBar(unit)))
+		opts = append(opts, AppendDecorators(decor.ETA(4, decor.DwidthSync)))
+	}
 	opts = append(opts, options...)
 	return p.AddBar(total, opts...)
 }
@@ -188,6 +388,35 @@ func (p *Progress) RemoveBar(b *Bar) bool {
 	}
 }
 
+// SetBarTotal is a shorthand for b.SetTotal(total), for code which prefers
+// to mutate bars through the container.
+func (p *Progress) SetBarTotal(b *Bar, total int64) {
+	b.SetTotal(total)
+}
+
+// SetFooter sets decorators, rendered as a line below the bars on every
+// refresh. As footer isn't a bar, decorators get zero Statistics, so they're
+// expected to get their data elsewhere, like CrossBar does. Calling SetFooter
+// without arguments removes the footer.
+func (p *Progress) SetFooter(fns ...decor.DecoratorFunc) {
+	select {
+	case p.ops <- func(c *pConf) {
+		c.footer = fns
+	}:
+	case <-p.quit:
+	}
+}
+
+// CrossBar provides decorator, which renders current values of bars a and b,
+// something like "%d of %d discovered" to be used in
+// fmt.Sprintf(format, aCurrent, bCurrent). Values are read lock free, so it's
+// safe to use as a footer (see SetFooter), or on any other bar.
+func CrossBar(a, b *Bar, format string) decor.DecoratorFunc {
+	return func(s *decor.Statistics, myWidth chan<- int, maxWidth <-chan int) string {
+		return fmt.Sprintf(format, a.currentNow(), b.currentNow())
+	}
+}
+
 // BarCount returns bars count
 func (p *Progress) BarCount() int {
 	result := make(chan int, 1)
@@ -202,17 +431,109 @@ func (p *Progress) BarCount() int {
 	}
 }
 
+// IsRunning returns true, until p has been stopped.
+// Bars added after that are dummy ones.
+func (p *Progress) IsRunning() bool {
+	select {
+	case <-p.quit:
+		return false
+	default:
+		return true
+	}
+}
+
+// AggregateCurrent returns sum of current progress of all bars
+func (p *Progress) AggregateCurrent() int64 {
+	result := make(chan int64, 1)
+	op := func(c *pConf) {
+		var sum int64
+		for _, b := range c.bars {
+			sum += b.Current()
+		}
+		result <- sum
+	}
+	select {
+	case p.ops <- op:
+		return <-result
+	case <-p.quit:
+		return 0
+	}
+}
+
+// AggregateTotal returns sum of totals of all bars, bars with unknown total
+// are skipped.
+func (p *Progress) AggregateTotal() int64 {
+	result := make(chan int64, 1)
+	op := func(c *pConf) {
+		var sum int64
+		for _, b := range c.bars {
+			if total := b.Total(); total > 0 {
+				sum += total
+			}
+		}
+		result <- sum
+	}
+	select {
+	case p.ops <- op:
+		return <-result
+	case <-p.quit:
+		return 0
+	}
+}
+
+// Frame returns lines of the last rendered frame, one per visible bar,
+// without any cursor control sequences. It's updated on every refresh, so
+// TUI hosts can place bars in their own layout (consider Output(ioutil.Discard)).
+func (p *Progress) Frame() []string {
+	result := make(chan []string, 1)
+	op := func(c *pConf) {
+		result <- append([]string(nil), c.frame...)
+	}
+	select {
+	case p.ops <- op:
+		return <-result
+	case <-p.quit:
+		<-p.done
+		return append([]string(nil), p.cacheConf.frame...)
+	}
+}
+
 // Stop is a way to gracefully shutdown mpb's rendering goroutine.
 // It is NOT for cancelation (use mpb.WithContext for cancelation purposes).
 // If *sync.WaitGroup has been provided via mpb.WithWaitGroup(), its Wait()
-// method will be called first.
+// method will be called first. External wg isn't required though: any bar,
+// which is still in progress, gets completed by Stop, so it's fine to just
+// increment bars up to their totals (or call Bar.Complete) and then Stop.
 func (p *Progress) Stop() {
+	p.stop(nil)
+}
+
+// StopStats is like Stop, but also returns aggregate statistics of all bars,
+// collected after bars have been completed, but before p.server quits.
+// Total counts only bars with known total, StartTime is the earliest one, and
+// TimeElapsed is measured from it. If p has been stopped already, ErrStopped
+// is returned.
+func (p *Progress) StopStats() (decor.Statistics, error) {
+	var stats decor.Statistics
+	ok := p.stop(func(c *pConf) {
+		stats = aggregateStatistics(c.bars)
+	})
+	if !ok {
+		return stats, ErrStopped
+	}
+	return stats, nil
+}
+
+// stop returns false, if p has been stopped already. If fn isn't nil, it's
+// called by p.server after all bars have quit (and final frame is rendered,
+// if requested by WithFinalRender).
+func (p *Progress) stop(fn func(*pConf)) bool {
 	if p.ewg != nil {
 		p.ewg.Wait()
 	}
 	select {
 	case <-p.quit:
-		return
+		return false
 	default:
 		// complete Total unknown bars
 		p.ops <- func(c *pConf) {
@@ -222,13 +543,114 @@ func (p *Progress) Stop() {
 		}
 		// wait for all bars to quit
 		p.wg.Wait()
+		done := make(chan struct{})
+		p.ops <- func(c *pConf) {
+			if c.finalRender {
+				renderFrame(c)
+			}
+			if c.pinned > 0 && !c.quiet {
+				c.cw.ResetScrollRegion()
+			}
+			// write pending log lines (see NewLogWriter) below the last frame
+			c.cw.ResetLineCount()
+			c.cw.Flush()
+			if c.summary != nil {
+				writeSummary(c.summary, c.bars)
+			}
+			notifyCompletion(c)
+			if fn != nil {
+				fn(c)
+			}
+			close(done)
+		}
+		<-done
 		// request p.server to quit
 		p.quitRequest()
 		// wait for p.server to quit
 		<-p.done
+		return true
 	}
 }
 
+// Reset makes p reusable for a new batch of bars, after it has been stopped.
+// If p is still running, it's stopped first. Bars of the previous batch are
+// cleared (their last frame is kept in the output) and rendering is started
+// again, with the same options. It's not safe to call Reset concurrently with
+// AddBar, or any other method of p.
+func (p *Progress) Reset() {
+	p.Stop()
+	<-p.done
+
+	conf := p.cacheConf
+	conf.bars = make([]*Bar, 0, 3)
+	conf.prependWidths = nil
+	conf.appendWidths = nil
+	conf.ticker.Stop()
+	conf.ticker = time.NewTicker(conf.rr)
+	// already closed by the previous p.server
+	conf.shutdownNotifier = nil
+	conf.cw.ResetLineCount()
+	conf.completionNotified = false
+
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.server(conf)
+}
+
+// writeSummary writes a line per bar, with its final amount, elapsed time and
+// average speed, in IEC units.
+func writeSummary(w io.Writer, bars []*Bar) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Bar\tAmount\tElapsed\tSpeed\t")
+	for _, b := range bars {
+		s := b.snapshot()
+		name := s.name
+		if name == "" {
+			name = strconv.Itoa(s.id)
+		}
+		elapsed, speed := "-", "-"
+		// bars are started by the first render too, so no progress means
+		// the bar hasn't really started
+		if !s.startTime.IsZero() && s.current > 0 {
+			end := s.doneTime
+			if end.IsZero() {
+				end = time.Now()
+			}
+			dur := end.Sub(s.startTime) - s.pausedFor()
+			elapsed = dur.Round(time.Second).String()
+			var rate float64
+			if dur > 0 {
+				rate = float64(s.current) / dur.Seconds()
+			}
+			speed = fmt.Sprintf("%s/s", decor.FormatF(rate).To(decor.Unit_KiB))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", name,
+			decor.Format(s.current).To(decor.Unit_KiB), elapsed, speed)
+	}
+	tw.Flush()
+}
+
+func aggregateStatistics(bars []*Bar) decor.Statistics {
+	stats := decor.Statistics{Completed: true}
+	for _, b := range bars {
+		bs := b.statistics()
+		if bs.Total > 0 {
+			stats.Total += bs.Total
+		}
+		stats.Current += bs.Current
+		stats.Completed = stats.Completed && bs.Completed
+		stats.Aborted = stats.Aborted || bs.Aborted
+		if !bs.StartTime.IsZero() &&
+			(stats.StartTime.IsZero() || bs.StartTime.Before(stats.StartTime)) {
+			stats.StartTime = bs.StartTime
+		}
+	}
+	if !stats.StartTime.IsZero() {
+		stats.TimeElapsed = time.Since(stats.StartTime)
+	}
+	return stats
+}
+
 func (p *Progress) quitRequest() {
 	select {
 	case <-p.quit:
@@ -244,6 +666,7 @@ func (p *Progress) server(conf pConf) {
 		if conf.shutdownNotifier != nil {
 			close(conf.shutdownNotifier)
 		}
+		p.cacheConf = conf
 		close(p.done)
 	}()
 
@@ -252,69 +675,199 @@ func (p *Progress) server(conf pConf) {
 		case op := <-p.ops:
 			op(&conf)
 		case <-conf.ticker.C:
-			numBars := len(conf.bars)
-			if numBars == 0 {
-				break
-			}
+			renderFrame(&conf)
+		case <-conf.cancel:
+			conf.ticker.Stop()
+			conf.cancel = nil
+		case <-p.quit:
+			// ticker is never collected, unless it's stopped
+			conf.ticker.Stop()
+			return
+		}
+	}
+}
 
-			if conf.beforeRender != nil {
-				conf.beforeRender(conf.bars)
-			}
+// renderFrame renders all bars once
+func renderFrame(conf *pConf) {
+	numBars := len(conf.bars)
+	if numBars == 0 {
+		return
+	}
 
-			wSyncTimeout := make(chan struct{})
-			time.AfterFunc(conf.rr, func() {
-				close(wSyncTimeout)
-			})
+	start := time.Now()
+	if conf.beforeRender != nil {
+		conf.beforeRender(conf.bars)
+	}
 
-			tw, th, _ := cwriter.GetTermSize()
-			// Default terminal is 80x24.
-			if th < 4 { // Need 1 line of context and one blank at the bottom
-				th = 24
-			}
-			if tw < 20 { // FIXME: Should count/size prependers
-				tw = 80
-			}
+	wSyncTimeout := make(chan struct{})
+	time.AfterFunc(conf.rr, func() {
+		close(wSyncTimeout)
+	})
 
-			// We want the last N bars, if we have too many it screws up
-			// the terminal display (and is unreadable anyway)...
-			bars := conf.bars[:]
-			skip := 0
-			th -= 3
-			if numBars > th {
-				skip = numBars - th
-			}
+	tw, th, _ := cwriter.GetTermSize()
+	// Default terminal is 80x24.
+	if th < 4 { // Need 1 line of context and one blank at the bottom
+		th = 24
+	}
+	if tw < 20 { // FIXME: Should count/size prependers
+		tw = 80
+	}
+	if conf.noTruncate {
+		// lines above the screen can't be redrawn, the cursor takes one
+		conf.cw.SetMaxLines(th - 1)
+	}
 
-			b0 := bars[0]
-			prependWs := newWidthSync(wSyncTimeout, numBars, b0.NumOfPrependers())
-			appendWs := newWidthSync(wSyncTimeout, numBars, b0.NumOfAppenders())
+	// We want the last N bars, if we have too many it screws up
+	// the terminal display (and is unreadable anyway)...
+	bars := conf.bars[:]
+	skip := 0
+	th -= 3
+	if conf.pinned > 0 {
+		th = conf.pinned
+	}
+	if conf.header != nil {
+		th--
+	}
+	if len(conf.footer) > 0 {
+		th--
+	}
+	if conf.columns > 1 {
+		th *= conf.columns
+	}
+	if numBars > th && !conf.noTruncate {
+		skip = numBars - th
+	}
 
-			flushed := make(chan struct{})
-			sequence := make([]<-chan []byte, numBars)
-			for i, b := range bars {
-				b.Update()
-				sequence[i] = b.render(tw, flushed, prependWs, appendWs)
-			}
+	// width of a single bar's line, columns are separated by a space
+	bw := tw
+	if conf.columns > 1 {
+		bw = tw/conf.columns - 1
+	}
 
-			for buf := range fanIn(skip, sequence...) {
-				conf.cw.Write(buf)
-			}
+	b0 := bars[0]
+	var prependWs, appendWs *widthSync
+	if conf.renderConcurrency > 0 {
+		conf.prependWidths = resizeWidths(conf.prependWidths, b0.NumOfPrependers())
+		conf.appendWidths = resizeWidths(conf.appendWidths, b0.NumOfAppenders())
+		prependWs = newLaggingWidthSync(numBars, conf.prependWidths)
+		appendWs = newLaggingWidthSync(numBars, conf.appendWidths)
+	} else {
+		prependWs = newWidthSync(wSyncTimeout, numBars, b0.NumOfPrependers())
+		appendWs = newWidthSync(wSyncTimeout, numBars, b0.NumOfAppenders())
+	}
 
-			for _, interceptor := range conf.interceptors {
-				interceptor(conf.cw)
-			}
+	flushed := make(chan struct{})
+	var sequence []<-chan []byte
+	if conf.renderConcurrency > 0 {
+		for _, b := range bars {
+			b.Update()
+		}
+		sequence = renderBounded(conf.renderConcurrency, bars, skip, bw, flushed, prependWs, appendWs)
+	} else {
+		sequence = make([]<-chan []byte, numBars)
+		for i, b := range bars {
+			b.Update()
+			sequence[i] = b.render(bw, i >= skip, flushed, prependWs, appendWs)
+		}
+	}
 
-			conf.cw.Flush()
-			close(flushed)
-		case <-conf.cancel:
-			conf.ticker.Stop()
-			conf.cancel = nil
-		case <-p.quit:
-			if conf.cancel != nil {
-				conf.ticker.Stop()
-			}
+	// whole frame is buffered, so it's written to the terminal at once, which
+	// matters on high latency connections. Extra outputs get the same frame,
+	// without interceptors' output.
+	var out, extra bytes.Buffer
+
+	// first buf can't be received, before width sync is done
+	if conf.header != nil {
+		header := []byte(strings.TrimRight(conf.header(), "\n") + "\n")
+		out.Write(header)
+		extra.Write(header)
+	}
+
+	for _, interceptor := range conf.preInterceptors {
+		interceptor(&out)
+	}
+
+	var syncDur time.Duration
+	lines := fanIn(skip, sequence...)
+	if conf.columns > 1 {
+		lines = joinColumns(conf.columns, bw, conf.widthCond, lines)
+	}
+	frame := make([]string, 0, numBars-skip)
+	for buf := range lines {
+		frame = append(frame, strings.TrimSuffix(string(buf), "\n"))
+		if syncDur == 0 {
+			syncDur = time.Since(start)
+		}
+		out.Write(buf)
+		extra.Write(buf)
+	}
+
+	if len(conf.footer) > 0 {
+		footer := renderFooter(conf.footer, wSyncTimeout)
+		out.Write(footer)
+		extra.Write(footer)
+	}
+
+	for _, interceptor := range conf.interceptors {
+		interceptor(&out)
+	}
+
+	if conf.pinned > 0 {
+		pinned := pinFrame(out.Bytes(), conf.pinned)
+		out.Reset()
+		out.Write(pinned)
+	}
+
+	if !conf.quiet {
+		conf.cw.Write(out.Bytes())
+		for _, w := range conf.extraOutputs {
+			w.Write(extra.Bytes())
+		}
+	}
+	conf.cw.Flush()
+	close(flushed)
+	conf.frame = frame
+	notifyCompletion(conf)
+
+	if conf.renderConcurrency > 0 {
+		conf.prependWidths = collectWidths(prependWs)
+		conf.appendWidths = collectWidths(appendWs)
+	}
+
+	renderDur := time.Since(start)
+	if renderDur > conf.rr {
+		conf.slowRenders++
+	} else {
+		conf.slowRenders = 0
+	}
+
+	if conf.debugLog != nil {
+		conf.debugLog.Printf("mpb: bars: %d, skipped: %d, width sync: %v, render: %v",
+			numBars, skip, syncDur, renderDur)
+		// warn once per streak of slow renders
+		if conf.slowRenders == slowRendersWarn {
+			conf.debugLog.Printf("mpb: warning: last %d renders took longer than refresh rate %v,"+
+				" consider greater refresh rate or fewer visible bars", slowRendersWarn, conf.rr)
+		}
+	}
+}
+
+// notifyCompletion calls conf.completionNotify once, if all bars have
+// completed. Bars, which have completed, but haven't quit yet, are caught by
+// the next call.
+func notifyCompletion(conf *pConf) {
+	if conf.completionNotify == nil || conf.completionNotified || len(conf.bars) == 0 {
+		return
+	}
+	for _, b := range conf.bars {
+		select {
+		case <-b.done:
+		default:
 			return
 		}
 	}
+	conf.completionNotified = true
+	conf.completionNotify()
 }
 
 func newWidthSync(timeout <-chan struct{}, numBars, numColumn int) *widthSync {
@@ -354,6 +907,76 @@ func newWidthSync(timeout <-chan struct{}, numBars, numColumn int) *widthSync {
 	return ws
 }
 
+// renderBounded renders bars by n workers. Returned sequence is in the bars
+// order, same as with b.render. Bars before skip aren't visible.
+func renderBounded(n int, bars []*Bar, skip, tw int, flushed chan struct{}, prependWs, appendWs *widthSync) []<-chan []byte {
+	sequence := make([]<-chan []byte, len(bars))
+	chans := make([]chan []byte, len(bars))
+	for i := range bars {
+		chans[i] = make(chan []byte, 1)
+		sequence[i] = chans[i]
+	}
+	jobs := make(chan int)
+	for w := 0; w < n; w++ {
+		go func() {
+			for i := range jobs {
+				bars[i].renderTo(chans[i], tw, i >= skip, flushed, prependWs, appendWs)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range bars {
+			jobs <- i
+		}
+	}()
+	return sequence
+}
+
+// newLaggingWidthSync returns widthSync, which never blocks. Its Result is
+// prefilled with widths collected on the previous render, while widths sent
+// to Listen are to be collected by collectWidths for the next one.
+func newLaggingWidthSync(numBars int, widths []int) *widthSync {
+	ws := &widthSync{
+		Listen: make([]chan int, len(widths)),
+		Result: make([]chan int, len(widths)),
+	}
+	for i, w := range widths {
+		ws.Listen[i] = make(chan int, numBars)
+		ws.Result[i] = make(chan int, numBars)
+		for j := 0; j < numBars; j++ {
+			ws.Result[i] <- w
+		}
+	}
+	return ws
+}
+
+// collectWidths returns max width per column, sent to ws.Listen so far
+func collectWidths(ws *widthSync) []int {
+	widths := make([]int, len(ws.Listen))
+	for i, ch := range ws.Listen {
+	loop:
+		for {
+			select {
+			case w := <-ch:
+				if w > widths[i] {
+					widths[i] = w
+				}
+			default:
+				break loop
+			}
+		}
+	}
+	return widths
+}
+
+func resizeWidths(widths []int, numColumn int) []int {
+	if len(widths) != numColumn {
+		return make([]int, numColumn)
+	}
+	return widths
+}
+
 func fanIn(skip int, inputs ...<-chan []byte) <-chan []byte {
 	ch := make(chan []byte)
 
@@ -361,7 +984,7 @@ func fanIn(skip int, inputs ...<-chan []byte) <-chan []byte {
 		defer close(ch)
 		for _, input := range inputs {
 			data := <-input
-			if skip > 1 {
+			if skip > 0 {
 				skip--
 				continue
 			}
@@ -372,6 +995,78 @@ func fanIn(skip int, inputs ...<-chan []byte) <-chan []byte {
 	return ch
 }
 
+// pinFrame makes frame to be rendered to the top lines of the terminal,
+// above scroll region set by WithPinnedRegion: cursor is saved, moved to the
+// top, and restored afterwards, so output of the program isn't disturbed.
+// Frame is truncated to lines.
+func pinFrame(frame []byte, lines int) []byte {
+	rows := bytes.Split(bytes.TrimSuffix(frame, []byte("\n")), []byte("\n"))
+	if len(rows) > lines {
+		rows = rows[:lines]
+	}
+	buf := make([]byte, 0, len(frame)+8*len(rows)+16)
+	// save cursor, move it home
+	buf = append(buf, "\x1b7\x1b[H"...)
+	for i, row := range rows {
+		if i > 0 {
+			buf = append(buf, "\r\n"...)
+		}
+		// row is cleared up to its end
+		buf = append(buf, row...)
+		buf = append(buf, "\x1b[K"...)
+	}
+	// restore cursor
+	return append(buf, "\x1b8"...)
+}
+
+// renderFooter renders footer decorators into a line. As footer isn't a bar,
+// decorators get zero Statistics.
+func renderFooter(fns []decor.DecoratorFunc, timeout <-chan struct{}) []byte {
+	ws := newWidthSync(timeout, 1, len(fns))
+	stat := new(decor.Statistics)
+	var buf []byte
+	for i, f := range fns {
+		buf = append(buf, f(stat, ws.Listen[i], ws.Result[i])...)
+	}
+	return append(buf, '\n')
+}
+
+// joinColumns joins every n lines from input into single line, space
+// separated, padding each one, but the last, to width.
+func joinColumns(n, width int, cond *runewidth.Condition, input <-chan []byte) <-chan []byte {
+	ch := make(chan []byte)
+	if cond == nil {
+		cond = runewidth.DefaultCondition
+	}
+
+	go func() {
+		defer close(ch)
+		var row []byte
+		var count int
+		for data := range input {
+			if count > 0 {
+				row = append(row, ' ')
+			}
+			data = bytes.TrimSuffix(data, []byte("\n"))
+			row = append(row, data...)
+			count++
+			if count == n {
+				ch <- append(row, '\n')
+				row, count = nil, 0
+				continue
+			}
+			if pad := width - cond.StringWidth(string(cwriter.StripEscapes(data))); pad > 0 {
+				row = append(row, bytes.Repeat([]byte{' '}, pad)...)
+			}
+		}
+		if count > 0 {
+			ch <- append(bytes.TrimRight(row, " "), '\n')
+		}
+	}()
+
+	return ch
+}
+
 func max(slice []int) int {
 	max := slice[0]
 
@@ -3,8 +3,14 @@ package mpb_test
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
 	"math/rand"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -97,6 +103,94 @@ func TestAddBar(t *testing.T) {
 	p.Stop()
 }
 
+func TestAddBars(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	numBars := 5
+	specs := make([]mpb.BarSpec, numBars)
+	for i := range specs {
+		specs[i] = mpb.BarSpec{Total: int64(10 * (i + 1)), Options: []mpb.BarOption{mpb.BarID(i)}}
+	}
+	bars := p.AddBars(specs)
+
+	if count := p.BarCount(); count != numBars {
+		t.Errorf("BarCount want: %d, got: %d\n", numBars, count)
+	}
+	for i, bar := range bars {
+		if bar.ID() != i {
+			t.Errorf("Bar ID want: %d, got: %d\n", i, bar.ID())
+		}
+		if bar.Total() != specs[i].Total {
+			t.Errorf("Bar total want: %d, got: %d\n", specs[i].Total, bar.Total())
+		}
+		bar.Incr(int(specs[i].Total))
+	}
+	p.Stop()
+}
+
+func TestSetBarTotal(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(100)
+	p.SetBarTotal(bar, 200)
+	if total := bar.Total(); total != 200 {
+		t.Errorf("Expected total: %d, got: %d\n", 200, total)
+	}
+	p.Stop()
+}
+
+func TestAddBarDefDynamic(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+	)
+	name := "first.txt"
+	bar := p.AddBarDefDynamic(100, func(s *decor.Statistics) string {
+		return name
+	}, decor.Unit_KiB)
+	name = "second.txt"
+	bar.Incr(100)
+	bar.Complete()
+	p.Stop()
+
+	if !strings.HasPrefix(buf.String(), "second.txt") {
+		t.Errorf("Expected dynamic name, got: %q\n", buf.String())
+	}
+}
+
+func TestAddBarDefCountdown(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+	)
+	bar := p.AddBarDefCountdown(500, "deleting ", 0)
+	bar.Incr(500)
+	bar.Complete()
+	p.Stop()
+
+	if !strings.HasPrefix(buf.String(), "deleting 0/500 ") {
+		t.Errorf("Expected remaining counter, got: %q\n", buf.String())
+	}
+}
+
+func TestAddTimerBar(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithRefreshRate(10*time.Millisecond))
+	bar := p.AddTimerBar(100*time.Millisecond, "cooldown ")
+
+	time.Sleep(50 * time.Millisecond)
+	if current := bar.Current(); current <= 0 || current >= int64(100*time.Millisecond) {
+		t.Errorf("Expected timer bar half way, got current: %v\n", time.Duration(current))
+	}
+	time.Sleep(150 * time.Millisecond)
+	if bar.InProgress() {
+		t.Error("Expected timer bar to complete on its own")
+	}
+	p.Stop()
+}
+
 func TestRemoveBar(t *testing.T) {
 	p := mpb.New()
 
@@ -113,6 +207,630 @@ func TestRemoveBar(t *testing.T) {
 	p.Stop()
 }
 
+func TestIsRunning(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	if !p.IsRunning() {
+		t.Error("Expected running progress")
+	}
+	p.Stop()
+	if p.IsRunning() {
+		t.Error("Expected stopped progress")
+	}
+}
+
+func TestReset(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	bar := p.AddBar(100)
+	bar.Incr(100)
+	p.Stop()
+
+	p.Reset()
+	if !p.IsRunning() {
+		t.Error("Expected running progress after Reset")
+	}
+	if count := p.BarCount(); count != 0 {
+		t.Errorf("BarCount want: %d, got: %d\n", 0, count)
+	}
+	bar, err := p.AddBarErr(50)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	bar.Incr(50)
+	stats, err := p.StopStats()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	if stats.Total != 50 || stats.Current != 50 {
+		t.Errorf("Want: 50/50, got: %d/%d\n", stats.Current, stats.Total)
+	}
+}
+
+func TestAddBarErr(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	if _, err := p.AddBarErr(100); err != nil {
+		t.Errorf("Unexpected error: %v\n", err)
+	}
+	p.Stop()
+	if _, err := p.AddBarErr(100); err != mpb.ErrStopped {
+		t.Errorf("Want: %v, got: %v\n", mpb.ErrStopped, err)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	b1 := p.AddBar(100)
+	b2 := p.AddBar(50)
+	b3 := p.AddBar(0)
+
+	b1.Incr(10)
+	b2.Incr(20)
+	b3.Incr(30)
+
+	if got := p.AggregateCurrent(); got != 60 {
+		t.Errorf("AggregateCurrent want: %d, got: %d\n", 60, got)
+	}
+	if got := p.AggregateTotal(); got != 150 {
+		t.Errorf("AggregateTotal want: %d, got: %d\n", 150, got)
+	}
+	p.Stop()
+}
+
+func TestStopStats(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	b1 := p.AddBar(100)
+	b2 := p.AddBar(50)
+
+	b1.Incr(100)
+	b2.Incr(50)
+
+	stats, err := p.StopStats()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	if stats.Total != 150 || stats.Current != 150 {
+		t.Errorf("Want: 150/150, got: %d/%d\n", stats.Current, stats.Total)
+	}
+	if !stats.Completed {
+		t.Error("Expected aggregate to be completed")
+	}
+	if stats.StartTime.IsZero() {
+		t.Error("Expected aggregate StartTime to be set")
+	}
+
+	if _, err := p.StopStats(); err != mpb.ErrStopped {
+		t.Errorf("Want: %v, got: %v\n", mpb.ErrStopped, err)
+	}
+}
+
+func TestWithFinalRender(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+	)
+	bar := p.AddBar(100, mpb.BarTrim())
+	bar.Incr(100)
+	// ticker never fires, so the only frame is the final one
+	bar.Complete()
+	p.Stop()
+
+	wantWidth := 80
+	gotWidth := utf8.RuneCount(buf.Bytes())
+	if gotWidth != wantWidth+1 { // +1 for new line
+		t.Errorf("Expected final frame width: %d, got: %d\n", wantWidth, gotWidth)
+	}
+}
+
+func TestWithOutputs(t *testing.T) {
+	var primary, extra bytes.Buffer
+	p := mpb.New(mpb.WithOutputs(&primary, &extra))
+	bar := p.AddBar(100, mpb.BarTrim())
+
+	for i := 0; i < 100; i++ {
+		time.Sleep(5 * time.Millisecond)
+		bar.Incr(1)
+	}
+	p.Stop()
+
+	if !bytes.Contains(primary.Bytes(), []byte{27}) {
+		t.Error("Expected cursor control sequences in primary output")
+	}
+	if extra.Len() == 0 {
+		t.Error("Expected output in extra writer")
+	}
+	if bytes.Contains(extra.Bytes(), []byte{27}) {
+		t.Errorf("Unexpected cursor control sequences in extra output: %q\n", extra.String())
+	}
+}
+
+func TestWithRenderConcurrency(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithRenderConcurrency(2))
+
+	numBars := 10
+	bars := make([]*mpb.Bar, numBars)
+	for i := 0; i < numBars; i++ {
+		name := fmt.Sprintf("Bar#%02d:", i)
+		bars[i] = p.AddBar(100, mpb.BarID(i),
+			mpb.PrependDecorators(decor.Name(name, 0, decor.DwidthSync)))
+	}
+	for i := 0; i < 10; i++ {
+		time.Sleep(30 * time.Millisecond)
+		for _, bar := range bars {
+			bar.Incr(10)
+		}
+	}
+	p.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < numBars {
+		t.Fatalf("Expected at least %d lines, got %d\n", numBars, len(lines))
+	}
+	lines = lines[len(lines)-numBars:]
+	for i, line := range lines {
+		want := fmt.Sprintf("Bar#%02d:", i)
+		if !strings.Contains(line, want) {
+			t.Errorf("Line %d: want %q, got %q\n", i, want, line)
+		}
+	}
+}
+
+func TestStopWithoutWaitGroup(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf))
+
+	numBars := 3
+	bars := make([]*mpb.Bar, numBars)
+	for i := 0; i < numBars; i++ {
+		bars[i] = p.AddBar(100, mpb.BarTrim())
+	}
+	for i, bar := range bars {
+		if i == numBars-1 {
+			// last one is completed explicitly
+			bar.Incr(50)
+			bar.Complete()
+			continue
+		}
+		bar.Incr(100)
+	}
+	p.Stop()
+
+	for i, bar := range bars {
+		if bar.InProgress() {
+			t.Errorf("Bar#%d: expected to be completed\n", i)
+		}
+	}
+	if p.IsRunning() {
+		t.Error("Expected stopped progress")
+	}
+}
+
+func TestWithColumns(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithColumns(2))
+
+	numBars := 3
+	for i := 0; i < numBars; i++ {
+		name := fmt.Sprintf("Bar#%d:", i)
+		bar := p.AddBar(100, mpb.BarID(i),
+			mpb.PrependDecorators(decor.StaticName(name, 0, 0)))
+		bar.Incr(100)
+	}
+	p.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected at least 2 lines, got %d\n", len(lines))
+	}
+	lines = lines[len(lines)-2:]
+	if !strings.Contains(lines[0], "Bar#0:") || !strings.Contains(lines[0], "Bar#1:") {
+		t.Errorf("Expected Bar#0 and Bar#1 on first row, got %q\n", lines[0])
+	}
+	if !strings.Contains(lines[1], "Bar#2:") {
+		t.Errorf("Expected Bar#2 on second row, got %q\n", lines[1])
+	}
+	for _, line := range lines {
+		if w := utf8.RuneCountInString(line); w > 80 {
+			t.Errorf("Row exceeds terminal width: %d\n", w)
+		}
+	}
+}
+
+func TestWithDumbTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithDumbTerminal())
+	bar := p.AddBar(100, mpb.BarTrim())
+
+	for i := 0; i < 100; i++ {
+		time.Sleep(2 * time.Millisecond)
+		bar.Incr(1)
+	}
+	p.Stop()
+
+	if buf.Len() == 0 {
+		t.Error("Expected some output")
+	}
+	if bytes.Contains(buf.Bytes(), []byte{27}) {
+		t.Errorf("Unexpected cursor control sequences in output: %q\n", buf.String())
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithHeader(func() string {
+		return "Downloading files..."
+	}))
+	bar := p.AddBar(100, mpb.BarTrim())
+
+	for i := 0; i < 100; i++ {
+		time.Sleep(2 * time.Millisecond)
+		bar.Incr(1)
+	}
+	p.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected at least 2 lines, got %d\n", len(lines))
+	}
+	if got := lines[len(lines)-2]; !strings.HasSuffix(got, "Downloading files...") {
+		t.Errorf("Expected header above the bar, got %q\n", got)
+	}
+}
+
+func TestWithSummaryTable(t *testing.T) {
+	var summary bytes.Buffer
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithSummaryTable(&summary))
+
+	b1 := p.AddBar(2048, mpb.BarID(1))
+	b2 := p.AddBar(3*1024*1024, mpb.BarID(2), mpb.BarName("big.iso"))
+	p.AddBar(100, mpb.BarID(3))
+	b1.Incr(2048)
+	b2.Incr(3 * 1024 * 1024)
+	// elapsed time is measured till completion, not till Stop
+	time.Sleep(1500 * time.Millisecond)
+	p.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(summary.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d: %q\n", len(lines), summary.String())
+	}
+	for i, want := range []string{"2.0KiB", "3.0MiB"} {
+		line := lines[i+1]
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in line %q\n", want, line)
+		}
+		if !strings.Contains(line, " 0s ") {
+			t.Errorf("Expected elapsed %q in line %q\n", "0s", line)
+		}
+	}
+	if !strings.HasPrefix(strings.TrimSpace(lines[2]), "big.iso") {
+		t.Errorf("Expected bar's name in line %q\n", lines[2])
+	}
+	if got := strings.Fields(lines[3]); len(got) != 4 || got[2] != "-" || got[3] != "-" {
+		t.Errorf("Expected no elapsed time and speed for bar never started, got %q\n", lines[3])
+	}
+}
+
+func TestFrame(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithHeader(func() string {
+		return "header"
+	}))
+
+	numBars := 3
+	for i := 0; i < numBars; i++ {
+		name := fmt.Sprintf("Bar#%d:", i)
+		bar := p.AddBar(100, mpb.BarID(i),
+			mpb.PrependDecorators(decor.StaticName(name, 0, 0)))
+		bar.Incr(50)
+	}
+	time.Sleep(250 * time.Millisecond)
+
+	frame := p.Frame()
+	if len(frame) != numBars {
+		t.Fatalf("Expected %d lines, got %d: %q\n", numBars, len(frame), frame)
+	}
+	for i, line := range frame {
+		want := fmt.Sprintf("Bar#%d:", i)
+		if !strings.HasPrefix(line, want) {
+			t.Errorf("Line %d: want prefix %q, got %q\n", i, want, line)
+		}
+		if strings.ContainsAny(line, "\x1b\n") {
+			t.Errorf("Line %d: unexpected control chars: %q\n", i, line)
+		}
+	}
+	p.Stop()
+	if frame := p.Frame(); len(frame) != numBars {
+		t.Errorf("Expected %d lines after Stop, got %d\n", numBars, len(frame))
+	}
+}
+
+func TestWithPreInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf),
+		mpb.WithPreInterceptor(func(w io.Writer) {
+			fmt.Fprintln(w, "status: ok")
+		}))
+	bar := p.AddBar(100, mpb.BarTrim())
+
+	for i := 0; i < 100; i++ {
+		time.Sleep(2 * time.Millisecond)
+		bar.Incr(1)
+	}
+	p.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected at least 2 lines, got %d\n", len(lines))
+	}
+	if got := lines[len(lines)-2]; !strings.HasSuffix(got, "status: ok") {
+		t.Errorf("Expected status above the bar, got %q\n", got)
+	}
+}
+
+func TestWithNoTruncate(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithNoTruncate())
+
+	// more bars than any (default 24 lines) terminal fits
+	numBars := 50
+	for i := 0; i < numBars; i++ {
+		bar := p.AddBar(100, mpb.BarID(i))
+		bar.Incr(50)
+	}
+	time.Sleep(250 * time.Millisecond)
+
+	if frame := p.Frame(); len(frame) != numBars {
+		t.Errorf("Expected %d lines, got %d\n", numBars, len(frame))
+	}
+	p.Stop()
+}
+
+func TestSetFooter(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard), mpb.WithDumbTerminal())
+	done := p.AddBar(100, mpb.BarID(0))
+	found := p.AddBar(100, mpb.BarID(1))
+	p.SetFooter(mpb.CrossBar(done, found, "%d of %d downloaded"))
+
+	found.Incr(40)
+	done.Incr(25)
+
+	var buf bytes.Buffer
+	p2 := mpb.New(mpb.Output(&buf))
+	p2.SetFooter(decor.StaticName("footer", 0, 0))
+	bar := p2.AddBar(100)
+	bar.Incr(100)
+	p2.Stop()
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if got := lines[len(lines)-1]; !strings.HasSuffix(got, "footer") {
+		t.Errorf("Expected footer below the bar, got %q\n", got)
+	}
+
+	got := mpb.CrossBar(done, found, "%d of %d downloaded")(nil, nil, nil)
+	if want := "25 of 40 downloaded"; got != want {
+		t.Errorf("Want: %q, got: %q\n", want, got)
+	}
+	p.Stop()
+}
+
+func TestWithCompletionNotify(t *testing.T) {
+	var count int32
+	p := mpb.New(
+		mpb.Output(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithCompletionNotify(func() {
+			atomic.AddInt32(&count, 1)
+		}),
+	)
+	for i := 0; i < 2; i++ {
+		bar := p.AddBar(10)
+		bar.Incr(10)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if got := atomic.LoadInt32(&count); got != 1 {
+		t.Errorf("Expected 1 notification before Stop, got: %d\n", got)
+	}
+	p.Stop()
+	if got := atomic.LoadInt32(&count); got != 1 {
+		t.Errorf("Expected 1 notification, got: %d\n", got)
+	}
+
+	var buf bytes.Buffer
+	p = mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithCompletionNotify(nil),
+	)
+	p.AddBar(0)
+	p.Stop()
+	if !strings.Contains(buf.String(), "\a") {
+		t.Errorf("Expected bell in the output, got: %q\n", buf.String())
+	}
+}
+
+func TestSlowRenderWarning(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(ioutil.Discard),
+		mpb.WithRefreshRate(time.Millisecond),
+		mpb.WithDebugLog(log.New(&buf, "", 0)),
+		mpb.WithBeforeRenderFunc(func([]*mpb.Bar) {
+			time.Sleep(2 * time.Millisecond)
+		}),
+	)
+	p.AddBar(100)
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+
+	if !strings.Contains(buf.String(), "took longer than refresh rate") {
+		t.Errorf("Expected slow render warning, got: %q\n", buf.String())
+	}
+}
+
+func TestNewInline(t *testing.T) {
+	var buf bytes.Buffer
+	p, bar := mpb.NewInline(100, &buf,
+		mpb.PrependDecorators(decor.StaticName("Downloading", 0, 0)))
+	bar.Incr(50)
+	time.Sleep(250 * time.Millisecond)
+	bar.Incr(50)
+	p.Stop()
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "\rDownloading") {
+		t.Errorf("Expected frames to start with carriage return, got: %q\n", out)
+	}
+	if strings.Contains(out, "\n") {
+		t.Errorf("Expected no new lines, got: %q\n", out)
+	}
+}
+
+func TestNewLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+	)
+	logger := log.New(mpb.NewLogWriter(p), "", 0)
+	bar := p.AddBar(100, mpb.BarTrim())
+	logger.Println("first")
+	bar.Incr(100)
+	bar.Complete()
+	p.Stop()
+	logger.Println("second")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != "first" || lines[2] != "second" {
+		t.Errorf("Expected log lines around the bar, got: %q\n", lines)
+	}
+}
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestFrameSingleWrite(t *testing.T) {
+	out := new(countingWriter)
+	p := mpb.New(
+		mpb.Output(out),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+		mpb.WithHeader(func() string { return "header" }),
+	)
+	for i := 0; i < 3; i++ {
+		bar := p.AddBar(100)
+		bar.Incr(100)
+		bar.Complete()
+	}
+	p.Stop()
+	if out.writes != 1 {
+		t.Errorf("Expected frame written at once, got %d writes: %q\n", out.writes, out.String())
+	}
+}
+
+func TestWithDebugLog(t *testing.T) {
+	var debug bytes.Buffer
+	p := mpb.New(
+		mpb.Output(ioutil.Discard),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+		mpb.WithDebugLog(log.New(&debug, "", 0)),
+	)
+	bar := p.AddBar(100)
+	bar.Incr(100)
+	bar.Complete()
+	p.Stop()
+	if !strings.HasPrefix(debug.String(), "mpb: bars: 1, skipped: 0,") {
+		t.Errorf("Expected a line per frame, got: %q\n", debug.String())
+	}
+}
+
+func TestWithoutDebugLog(t *testing.T) {
+	// nothing may go to the standard logger either
+	var std, out bytes.Buffer
+	log.SetOutput(&std)
+	defer log.SetOutput(os.Stderr)
+	p := mpb.New(mpb.Output(&out), mpb.WithRefreshRate(time.Hour), mpb.WithFinalRender())
+	bar := p.AddBar(100)
+	bar.Incr(100)
+	bar.Complete()
+	p.Stop()
+	if std.Len() != 0 || strings.Contains(out.String(), "mpb:") {
+		t.Errorf("Expected no debug output, got: %q, %q\n", std.String(), out.String())
+	}
+}
+
+func TestNewLogWriterNoBars(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf), mpb.WithRefreshRate(time.Hour))
+	logger := log.New(mpb.NewLogWriter(p), "", 0)
+	logger.Println("first")
+	// round trip to p's goroutine, so the log line has been handled
+	p.BarCount()
+	if got, want := buf.String(), "first\n"; got != want {
+		t.Errorf("Expected log line written at once, want: %q, got: %q\n", want, got)
+	}
+	p.Stop()
+}
+
+func TestWithQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithFinalRender(),
+		mpb.WithQuiet(),
+		mpb.WithPinnedRegion(1),
+		mpb.WithCompletionNotify(nil),
+	)
+	bar := p.AddBar(100)
+	bar.Incr(100)
+	time.Sleep(50 * time.Millisecond)
+	if bar.InProgress() {
+		t.Error("Expected bar to complete in quiet mode")
+	}
+	stats, err := p.StopStats()
+	if err != nil || !stats.Completed || stats.Current != 100 {
+		t.Errorf("Want: completed 100, got: %v %d (%v)\n", stats.Completed, stats.Current, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got: %q\n", buf.String())
+	}
+}
+
+func TestWithPinnedRegion(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.Output(&buf),
+		mpb.WithRefreshRate(time.Hour),
+		mpb.WithFinalRender(),
+		mpb.WithPinnedRegion(1),
+	)
+	bar := p.AddBar(100)
+	bar.Incr(100)
+	bar.Complete()
+	p.Stop()
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "\x1b[2;") {
+		t.Errorf("Expected scroll region below the first line, got: %q\n", out)
+	}
+	if !strings.Contains(out, "\x1b7\x1b[H") {
+		t.Errorf("Expected frame rendered at the top, got: %q\n", out)
+	}
+	if !strings.HasSuffix(out, "\x1b[r") {
+		t.Errorf("Expected scroll region restored, got: %q\n", out)
+	}
+}
+
 func TestWithCancel(t *testing.T) {
 	cancel := make(chan struct{})
 	shutdown := make(chan struct{})
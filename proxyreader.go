@@ -1,21 +1,110 @@
 package mpb
 
-import "io"
+import (
+	"errors"
+	"io"
+)
 
-// Reader is io.Reader wrapper, for proxy read bytes
+// ErrReadLimit is returned by Reader, created by Bar.ProxyReaderLimit, once
+// more than the bar's total bytes have been read.
+var ErrReadLimit = errors.New("mpb: read more than bar's total")
+
+// writeToBufSize is the chunk size used by Reader.WriteTo, when destination
+// isn't an io.ReaderFrom.
+const writeToBufSize = 256 * 1024
+
+// Reader is io.Reader wrapper, for proxy read bytes. If underlying reader
+// returns an error other than io.EOF, bars are aborted, see KeepOnError.
 type Reader struct {
 	io.Reader
-	bar *Bar
+	bars []*Bar
+
+	// if > 0, bar is incremented only once flushEvery bytes are read
+	flushEvery int64
+	pending    int64
+
+	// if true, bars aren't aborted on read error
+	noAbort bool
+
+	// if not nil, called with number of bytes of each read
+	cb func(n int)
+
+	// if > 0, reading more than limit bytes fails with ErrReadLimit
+	limit int64
+	read  int64
+}
+
+// readerOnly hides WriteTo of the embedded Reader, so io.Copy style helpers
+// don't recurse back into it.
+type readerOnly struct {
+	io.Reader
+}
+
+// MultiProxyReader wraps r, so every read is accounted to each of bars, like
+// a per file bar and an aggregate one.
+func MultiProxyReader(r io.Reader, bars ...*Bar) io.Reader {
+	return &Reader{Reader: r, bars: bars}
+}
+
+// KeepOnError makes r not to abort its bars, if underlying reader returns
+// an error other than io.EOF. Useful to retry on the same bar.
+func (r *Reader) KeepOnError() *Reader {
+	r.noAbort = true
+	return r
 }
 
 func (r *Reader) Read(p []byte) (int, error) {
 	n, err := r.Reader.Read(p)
-	r.bar.Incr(n)
+	if r.limit > 0 {
+		if r.read+int64(n) > r.limit {
+			n, err = int(r.limit-r.read), ErrReadLimit
+		}
+		r.read += int64(n)
+	}
+	r.incr(n, err != nil)
+	if r.cb != nil {
+		r.cb(n)
+	}
+	if err != nil && err != io.EOF && !r.noAbort {
+		for _, b := range r.bars {
+			b.Abort()
+		}
+	}
 	return n, err
 }
 
+func (r *Reader) incr(n int, flush bool) {
+	if r.flushEvery <= 0 {
+		r.incrBars(n)
+		return
+	}
+	r.pending += int64(n)
+	if r.pending > 0 && (flush || r.pending >= r.flushEvery) {
+		r.incrBars(int(r.pending))
+		r.pending = 0
+	}
+}
+
+func (r *Reader) incrBars(n int) {
+	for _, b := range r.bars {
+		b.Incr(n)
+	}
+}
+
+// WriteTo implements io.WriterTo, so io.Copy doesn't fall back to its own
+// small buffer. If w implements io.ReaderFrom, it's used directly, otherwise
+// data is copied in large chunks. Bar is incremented per chunk read.
+func (r *Reader) WriteTo(w io.Writer) (int64, error) {
+	if rf, ok := w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(readerOnly{r})
+	}
+	buf := make([]byte, writeToBufSize)
+	return io.CopyBuffer(w, readerOnly{r}, buf)
+}
+
 // Close the reader when it implements io.Closer
 func (r *Reader) Close() error {
+	r.incr(0, true)
 	if closer, ok := r.Reader.(io.Closer); ok {
 		return closer.Close()
 	}
@@ -8,8 +8,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/james-antill/mpb"
+	"github.com/james-antill/mpb/decor"
 )
 
 const content = `Lorem ipsum dolor sit amet, consectetur adipisicing elit, sed do
@@ -79,6 +81,203 @@ func TestProxyReaderCloser(t *testing.T) {
 	}
 }
 
+func TestProxyReaderWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf))
+
+	total := int64(len(content))
+	bar := p.AddBar(total, mpb.BarTrim())
+	preader := bar.ProxyReader(strings.NewReader(content))
+
+	var dest bytes.Buffer
+	written, err := preader.WriteTo(&dest)
+	if err != nil {
+		t.Errorf("Error writing from reader: %+v\n", err)
+	}
+
+	p.Stop()
+
+	if written != total {
+		t.Errorf("Expected written: %d, got: %d\n", total, written)
+	}
+	if dest.String() != content {
+		t.Errorf("Expected content to be copied unchanged\n")
+	}
+	if current := bar.Current(); current != total {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+}
+
+func TestProxyReaderBuffered(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf))
+
+	total := int64(len(content))
+	bar := p.AddBar(total+1, mpb.BarTrim())
+	preader := bar.ProxyReaderBuffered(iotest.OneByteReader(strings.NewReader(content)), 64)
+
+	if _, err := io.CopyN(ioutil.Discard, preader, 63); err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+	if current := bar.Current(); current != 0 {
+		t.Errorf("Expected no flush before 64 bytes, got current: %d\n", current)
+	}
+
+	if _, err := io.Copy(ioutil.Discard, preader); err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+	if current := bar.Current(); current != total {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+
+	p.Stop()
+}
+
+func TestMultiProxyReader(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	total := int64(len(content))
+	fileBar := p.AddBar(total)
+	totalBar := p.AddBar(2 * total)
+
+	preader := mpb.MultiProxyReader(strings.NewReader(content), fileBar, totalBar)
+	if _, err := io.Copy(ioutil.Discard, preader); err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+	for _, bar := range []*mpb.Bar{fileBar, totalBar} {
+		if current := bar.Current(); current != total {
+			t.Errorf("Expected current: %d, got: %d\n", total, current)
+		}
+	}
+
+	p.Stop()
+}
+
+func TestProxyReaderAbort(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	bar := p.AddBar(int64(len(content)))
+	preader := bar.ProxyReader(iotest.TimeoutReader(strings.NewReader(content)))
+	buf := make([]byte, 8)
+	if _, err := preader.Read(buf); err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	if _, err := preader.Read(buf); err != iotest.ErrTimeout {
+		t.Fatalf("Want: %v, got: %v\n", iotest.ErrTimeout, err)
+	}
+
+	stats, _ := p.StopStats()
+	if !stats.Aborted {
+		t.Error("Expected bar to be aborted")
+	}
+	if current := bar.Current(); current != 8 {
+		t.Errorf("Expected current: %d, got: %d\n", 8, current)
+	}
+}
+
+func TestProxyReaderLimit(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	bar := p.AddBar(10)
+	preader := bar.ProxyReaderLimit(strings.NewReader(content))
+	n, err := io.Copy(ioutil.Discard, preader)
+	if err != mpb.ErrReadLimit {
+		t.Errorf("Want: %v, got: %v\n", mpb.ErrReadLimit, err)
+	}
+	if n != 10 {
+		t.Errorf("Expected copied: %d, got: %d\n", 10, n)
+	}
+
+	stats, _ := p.StopStats()
+	if !stats.Aborted {
+		t.Error("Expected bar to be aborted")
+	}
+}
+
+func TestProxyReaderKeepOnError(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	bar := p.AddBar(int64(len(content)))
+	preader := bar.ProxyReader(iotest.TimeoutReader(strings.NewReader(content))).KeepOnError()
+	buf := make([]byte, 8)
+	preader.Read(buf)
+	preader.Read(buf)
+	if !bar.InProgress() {
+		t.Error("Expected bar to be in progress")
+	}
+	p.Stop()
+}
+
+func TestProxyReaderFunc(t *testing.T) {
+	p := mpb.New(mpb.Output(ioutil.Discard))
+
+	total := int64(len(content))
+	bar := p.AddBar(total)
+	var sum int64
+	preader := bar.ProxyReaderFunc(strings.NewReader(content), func(n int) {
+		sum += int64(n)
+	})
+	if _, err := io.Copy(ioutil.Discard, preader); err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+	if sum != total {
+		t.Errorf("Expected callback sum: %d, got: %d\n", total, sum)
+	}
+	if current := bar.Current(); current != total {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+
+	p.Stop()
+}
+
+func TestProxyReaderPipe(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.Output(&buf))
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < 3; i++ {
+			io.WriteString(pw, content)
+		}
+		pw.Close()
+	}()
+
+	bar := p.AddBarDef(0, "pipe:", decor.Unit_kB)
+	written, err := io.Copy(ioutil.Discard, bar.ProxyReader(pr))
+	if err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+
+	total := int64(3 * len(content))
+	if written != total {
+		t.Errorf("Expected written: %d, got: %d\n", total, written)
+	}
+	if current := bar.Current(); current != total {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+
+	p.Stop()
+}
+
+func BenchmarkRawCopy(b *testing.B) {
+	data := bytes.Repeat([]byte(content), 1<<12)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		io.Copy(ioutil.Discard, bytes.NewReader(data))
+	}
+}
+
+func BenchmarkProxyReaderCopy(b *testing.B) {
+	data := bytes.Repeat([]byte(content), 1<<12)
+	p := mpb.New(mpb.Output(ioutil.Discard))
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		bar := p.AddBar(int64(len(data)))
+		io.Copy(ioutil.Discard, bar.ProxyReader(bytes.NewReader(data)))
+	}
+	p.Stop()
+}
+
 func setupTestHttpServer(content string) *httptest.Server {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {